@@ -0,0 +1,156 @@
+package activity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+const dayLayout = "2006-01-02"
+
+// Summary represents the workload of an owner in a range of days
+type Summary struct {
+	Owner  string    `json:"owner"`
+	Region string    `json:"region"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Days   int       `json:"days"`
+	Events int       `json:"events"`
+	// ActiveDays with events, and EmptyDays without public agenda
+	ActiveDays    int     `json:"activeDays"`
+	EmptyDays     int     `json:"emptyDays"`
+	EventsPerDay  float64 `json:"eventsPerDay"`
+	EventsPerWeek float64 `json:"eventsPerWeek"`
+	// Earliest and Latest scheduled times, as "HH:MM", of the events with known time
+	Earliest      string `json:"earliest"`
+	Latest        string `json:"latest"`
+	WeekendEvents int    `json:"weekendEvents"`
+	WeekendDays   int    `json:"weekendDays"`
+	HolidayEvents int    `json:"holidayEvents"`
+	HolidayDays   int    `json:"holidayDays"`
+	// Heatmap of the events with known time, per weekday, from Monday, and hour
+	Heatmap [7][24]int `json:"heatmap"`
+}
+
+// timed checks if the time of an event is known. Events indexed without time precision
+// are timed unless they are at midnight
+func timed(event models.AgendaEvent) bool {
+	if event.TimePrecision != "" {
+		return event.TimePrecision == models.MinutePrecision
+	}
+
+	return event.Date.Hour() != 0 || event.Date.Minute() != 0
+}
+
+// Summarize returns the workload of each owner from the first to the last day, both
+// included. If any of them is zero, the first or the last day of the events of the owner
+// are used
+func Summarize(events []models.AgendaEvent, from time.Time, to time.Time, isHoliday func(region string, day time.Time) bool) []Summary {
+	type key struct {
+		owner  string
+		region string
+	}
+
+	byOwner := map[key][]models.AgendaEvent{}
+	keys := []key{}
+	for _, event := range events {
+		k := key{owner: event.Owner, region: event.Region}
+		if _, ok := byOwner[k]; !ok {
+			keys = append(keys, k)
+		}
+		byOwner[k] = append(byOwner[k], event)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].region != keys[j].region {
+			return keys[i].region < keys[j].region
+		}
+		return keys[i].owner < keys[j].owner
+	})
+
+	summaries := []Summary{}
+	for _, k := range keys {
+		agenda := byOwner[k]
+		summary := Summary{Owner: k.owner, Region: k.region, From: day(from), To: day(to)}
+
+		perDay := map[string]int{}
+		earliest, latest := -1, -1
+		for _, event := range agenda {
+			date := day(event.Date)
+			if summary.From.IsZero() || (from.IsZero() && date.Before(summary.From)) {
+				summary.From = date
+			}
+			if summary.To.IsZero() || (to.IsZero() && date.After(summary.To)) {
+				summary.To = date
+			}
+
+			summary.Events++
+			perDay[date.Format(dayLayout)]++
+
+			if !timed(event) {
+				continue
+			}
+
+			minutes := event.Date.Hour()*60 + event.Date.Minute()
+			if earliest < 0 || minutes < earliest {
+				earliest = minutes
+			}
+			if minutes > latest {
+				latest = minutes
+			}
+
+			weekday := (int(event.Date.Weekday()) + 6) % 7
+			summary.Heatmap[weekday][event.Date.Hour()]++
+		}
+
+		if earliest >= 0 {
+			summary.Earliest = fmt.Sprintf("%02d:%02d", earliest/60, earliest%60)
+			summary.Latest = fmt.Sprintf("%02d:%02d", latest/60, latest%60)
+		}
+
+		for date := summary.From; !date.After(summary.To); date = date.AddDate(0, 0, 1) {
+			summary.Days++
+
+			count := perDay[date.Format(dayLayout)]
+			if count > 0 {
+				summary.ActiveDays++
+			} else {
+				summary.EmptyDays++
+			}
+
+			if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+				summary.WeekendEvents += count
+				if count > 0 {
+					summary.WeekendDays++
+				}
+			}
+
+			if isHoliday != nil && isHoliday(k.region, date) {
+				summary.HolidayEvents += count
+				if count > 0 {
+					summary.HolidayDays++
+				}
+			}
+		}
+
+		if summary.Days > 0 {
+			summary.EventsPerDay = float64(summary.Events) / float64(summary.Days)
+			summary.EventsPerWeek = summary.EventsPerDay * 7
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// day returns the midnight of the day of a date, in UTC, to iterate over days
+func day(date time.Time) time.Time {
+	if date.IsZero() {
+		return date
+	}
+
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,89 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestSummarize(t *testing.T) {
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2020, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	event := func(date time.Time, precision string) models.AgendaEvent {
+		return models.AgendaEvent{Date: date, Owner: "Presidente", Region: "Madrid", TimePrecision: precision}
+	}
+
+	events := []models.AgendaEvent{
+		// Monday 2
+		event(at(2, 9, 30), models.MinutePrecision),
+		event(at(2, 19, 0), models.MinutePrecision),
+		// Thursday 5, a holiday
+		event(at(5, 12, 0), models.MinutePrecision),
+		// Saturday 7, untimed events
+		event(at(7, 0, 0), models.DayPrecision),
+		event(at(7, 9, 0), models.PartOfDayPrecision),
+		// Sunday 8, indexed without precision at midnight
+		event(at(8, 0, 0), ""),
+		event(at(8, 8, 15), ""),
+		{Date: at(3, 10, 0), Owner: "Consejero de Sanidad", Region: "Madrid", TimePrecision: models.MinutePrecision},
+	}
+	isHoliday := func(region string, day time.Time) bool {
+		return region == "Madrid" && day.Day() == 5
+	}
+
+	summaries := Summarize(events, at(2, 0, 0), at(8, 0, 0), isHoliday)
+	if len(summaries) != 2 || summaries[0].Owner != "Consejero de Sanidad" {
+		t.Fatalf("unexpected summaries %+v", summaries)
+	}
+
+	summary := summaries[1]
+	if summary.Days != 7 || summary.Events != 7 || summary.ActiveDays != 4 || summary.EmptyDays != 3 {
+		t.Errorf("unexpected days %+v", summary)
+	}
+	if summary.EventsPerDay != 1 || summary.EventsPerWeek != 7 {
+		t.Errorf("unexpected rates %v, %v", summary.EventsPerDay, summary.EventsPerWeek)
+	}
+	if summary.Earliest != "08:15" || summary.Latest != "19:00" {
+		t.Errorf("unexpected times %s - %s", summary.Earliest, summary.Latest)
+	}
+	if summary.WeekendEvents != 4 || summary.WeekendDays != 2 {
+		t.Errorf("unexpected weekend %d events in %d days", summary.WeekendEvents, summary.WeekendDays)
+	}
+	if summary.HolidayEvents != 1 || summary.HolidayDays != 1 {
+		t.Errorf("unexpected holidays %d events in %d days", summary.HolidayEvents, summary.HolidayDays)
+	}
+
+	heatmap := 0
+	for _, hours := range summary.Heatmap {
+		for _, count := range hours {
+			heatmap += count
+		}
+	}
+	if heatmap != 4 || summary.Heatmap[0][9] != 1 || summary.Heatmap[0][19] != 1 || summary.Heatmap[3][12] != 1 || summary.Heatmap[6][8] != 1 {
+		t.Errorf("unexpected heatmap %v", summary.Heatmap)
+	}
+
+	// the days of the other owner are from its first to its last event
+	if other := summaries[0]; other.Days != 7 || other.ActiveDays != 1 || other.EmptyDays != 6 {
+		t.Errorf("unexpected summary %+v", other)
+	}
+}
+
+func TestSummarizeOpenRange(t *testing.T) {
+	events := []models.AgendaEvent{
+		{Date: time.Date(2020, 3, 4, 10, 0, 0, 0, time.UTC), Owner: "Presidente", Region: "Madrid"},
+		{Date: time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC), Owner: "Presidente", Region: "Madrid"},
+	}
+
+	summaries := Summarize(events, time.Time{}, time.Time{}, nil)
+	if len(summaries) != 1 {
+		t.Fatalf("unexpected summaries %+v", summaries)
+	}
+
+	summary := summaries[0]
+	if summary.From.Day() != 2 || summary.To.Day() != 4 || summary.Days != 3 || summary.EmptyDays != 1 || summary.HolidayDays != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
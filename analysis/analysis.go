@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+)
+
+var accentsReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"ä", "a", "ë", "e", "ï", "i", "ö", "o", "ü", "u",
+	"â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// Normalize lowercases a text, folds Spanish accents and replaces anything that is not
+// a letter or a digit with a single space, so that texts can be compared word by word
+func Normalize(text string) string {
+	text = accentsReplacer.Replace(strings.ToLower(text))
+
+	var sb strings.Builder
+	space := true
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			space = false
+		} else if !space {
+			sb.WriteRune(' ')
+			space = true
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Castilla-La Mancha":      "castilla la mancha",
+		"  Reunión  con  D. Ñ.  ": "reunion con d n",
+		"Valdepeñas (C. Real)":    "valdepenas c real",
+		"":                        "",
+	}
+
+	for text, normalized := range tests {
+		if got := Normalize(text); got != normalized {
+			t.Errorf("Normalize(%q) = %q, want %q", text, got, normalized)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Visita a los hospitales y las reuniones de la Consejería")
+	expected := []string{"visita", "hospital", "reunion", "consejeria"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokenize = %v, want %v", tokens, expected)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	documents := [][]string{
+		Tokenize("Inauguración del hospital de Toledo"),
+		Tokenize("Inauguración del nuevo hospital de Toledo"),
+		Tokenize("Reunión con los sindicatos agrarios"),
+	}
+	corpus := NewCorpus(documents)
+
+	same := Cosine(corpus.Vector(documents[0]), corpus.Vector(documents[1]))
+	different := Cosine(corpus.Vector(documents[0]), corpus.Vector(documents[2]))
+	if same < 0.7 || different != 0 {
+		t.Errorf("unexpected similarities %.3f and %.3f", same, different)
+	}
+}
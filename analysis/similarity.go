@@ -0,0 +1,202 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+)
+
+// Similarity returns how similar two names are, from 0 to 1. Their normalised words are
+// compared regardless of their order, so that typos and reorderings score high
+func Similarity(a string, b string) float64 {
+	wordsA := strings.Fields(Normalize(a))
+	wordsB := strings.Fields(Normalize(b))
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	sort.Strings(wordsA)
+	sort.Strings(wordsB)
+
+	common := 0
+	inB := map[string]int{}
+	for _, w := range wordsB {
+		inB[w]++
+	}
+	for _, w := range wordsA {
+		if inB[w] > 0 {
+			inB[w]--
+			common++
+		}
+	}
+	dice := 2 * float64(common) / float64(len(wordsA)+len(wordsB))
+
+	jw := jaroWinkler(strings.Join(wordsA, " "), strings.Join(wordsB, " "))
+	if jw > dice {
+		return jw
+	}
+
+	return dice
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings, from 0 to 1
+func jaroWinkler(a string, b string) float64 {
+	ra := []rune(a)
+	rb := []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := len(ra)
+	if len(rb) > window {
+		window = len(rb)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		from := i - window
+		if from < 0 {
+			from = 0
+		}
+		to := i + window + 1
+		if to > len(rb) {
+			to = len(rb)
+		}
+
+		for j := from; j < to; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i] = true
+				matchedB[j] = true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < len(ra) && prefix < len(rb) && prefix < 4 && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// legal forms of Spanish companies, not identifying an organization
+var legalForms = map[string]bool{
+	"sa": true, "sl": true, "slu": true, "sau": true, "sll": true, "scl": true, "coop": true,
+	"sociedad": true, "anonima": true, "limitada": true, "unipersonal": true,
+}
+
+// OrganizationSimilarity returns how similar the name of an organization mentioned in an
+// event is to a registered name, from 0 to 1, comparing their distinctive words. An acronym
+// is the same organization as the names including it as a word
+func OrganizationSimilarity(mention string, name string) float64 {
+	acronym := len(mention) >= 3 && strings.ToUpper(mention) == mention && !strings.Contains(mention, " ")
+	if acronym && strings.Contains(" "+Normalize(name)+" ", " "+Normalize(mention)+" ") {
+		return 1
+	}
+
+	return TokenSimilarity(mention, name)
+}
+
+// genericWords are the normalised words naming the kind of an organization rather than
+// identifying it, so that "Fundación Telefónica" and "Fundación Iberdrola" are told apart
+var genericWords = map[string]bool{
+	"fundacion": true, "asociacion": true, "federacion": true, "confederacion": true,
+	"grupo": true, "empresa": true, "compania": true, "corporacion": true, "consorcio": true,
+	"sindicato": true, "union": true, "camara": true, "plataforma": true,
+}
+
+// TokenSimilarity returns how similar two names are, from 0 to 1, pairing their distinctive
+// words: stop words, legal forms and generic words as "fundacion" are ignored, and each
+// remaining word scores only when paired with a similar word of the other name. Names
+// with different generic words, as "Fundación Iberdrola" and "Iberdrola", compare all their
+// words
+func TokenSimilarity(a string, b string) float64 {
+	tokensA, genericA := distinctiveTokens(a)
+	tokensB, genericB := distinctiveTokens(b)
+	if genericA != genericB {
+		tokensA = append(tokensA, strings.Fields(genericA)...)
+		tokensB = append(tokensB, strings.Fields(genericB)...)
+	}
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	paired := make([]bool, len(tokensB))
+	total := 0.0
+	for _, tokenA := range tokensA {
+		best := -1
+		bestScore := 0.0
+		for j, tokenB := range tokensB {
+			if paired[j] {
+				continue
+			}
+
+			score := jaroWinkler(tokenA, tokenB)
+			if score > bestScore {
+				best = j
+				bestScore = score
+			}
+		}
+
+		if best >= 0 {
+			paired[best] = true
+			total += bestScore
+		}
+	}
+
+	return 2 * total / float64(len(tokensA)+len(tokensB))
+}
+
+// distinctiveTokens returns the normalised words of a name identifying it, and its sorted
+// generic words. Names made only of generic words are identified by all of them
+func distinctiveTokens(name string) ([]string, string) {
+	distinctive := []string{}
+	generic := []string{}
+	for _, word := range strings.Fields(Normalize(name)) {
+		if len(word) == 1 || stopWords[word] || legalForms[word] {
+			continue
+		}
+
+		if genericWords[word] {
+			generic = append(generic, word)
+		} else {
+			distinctive = append(distinctive, word)
+		}
+	}
+
+	if len(distinctive) == 0 {
+		return generic, ""
+	}
+
+	sort.Strings(generic)
+
+	return distinctive, strings.Join(generic, " ")
+}
@@ -0,0 +1,39 @@
+package analysis
+
+import "testing"
+
+func TestTokenSimilarity(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		same bool
+	}{
+		{"Fundación Telefónica", "Telefónica, Fundación", true},
+		{"Emiliano García-Page", "Emiliano Garcia Page", true},
+		{"Emiliano García Paje", "Emiliano García Page", true},
+		{"Iberdrola, S.A.", "Iberdrola", true},
+		{"Fundación", "fundacion", true},
+		{"de fundacion telefonica", "de fundacion iberdrola", false},
+		{"Fundación Telefónica", "Fundación Iberdrola", false},
+		{"abengoa", "abengoa solar", false},
+		{"Fundación Iberdrola", "Iberdrola", false},
+		{"Asociación de Vecinos", "Asociación de Empresarios", false},
+		{"", "Iberdrola", false},
+	}
+
+	for _, test := range tests {
+		score := TokenSimilarity(test.a, test.b)
+		if (score >= 0.9) != test.same {
+			t.Errorf("TokenSimilarity(%q, %q) = %.3f, same = %v", test.a, test.b, score, test.same)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if score := Similarity("García Page, Emiliano", "Emiliano García Page"); score != 1 {
+		t.Errorf("reordered names scored %.3f", score)
+	}
+	if score := Similarity("", "Emiliano"); score != 0 {
+		t.Errorf("empty name scored %.3f", score)
+	}
+}
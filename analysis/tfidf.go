@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+)
+
+// stopWords are the normalised Spanish words not carrying meaning on their own
+var stopWords = map[string]bool{}
+
+func init() {
+	words := `a al algo algunas algunos ante antes aquel aquella aquellos asi aun bajo bien cada
+		como con contra cual cuales cuando de del desde donde durante e el ella ellas ellos en
+		entre era eran es esa esas ese eso esos esta estan estar estas este esto estos fue fueron
+		ha han hasta hay he hemos la las le les lo los mas me mi mis mucho muchos muy nada ni no
+		nos nosotros o os otra otras otro otros para pero poco por porque que quien quienes se
+		sea sean ser sera seran si sido sin sobre son su sus tambien tanto te tiene tienen todo
+		todos tras tu tus un una unas uno unos y ya`
+
+	for _, word := range strings.Fields(words) {
+		stopWords[word] = true
+	}
+}
+
+// Tokenize normalises a text and splits it into stemmed words, removing Spanish stop words
+func Tokenize(text string) []string {
+	tokens := []string{}
+	for _, word := range strings.Fields(Normalize(text)) {
+		if stopWords[word] || len(word) < 2 {
+			continue
+		}
+
+		tokens = append(tokens, stem(word))
+	}
+
+	return tokens
+}
+
+// stem applies a light Spanish stemming, removing plurals so that "hospitales" and
+// "hospital" share the same token
+func stem(word string) string {
+	if len(word) <= 4 {
+		return word
+	}
+
+	if strings.HasSuffix(word, "iones") {
+		return strings.TrimSuffix(word, "es")
+	}
+
+	if strings.HasSuffix(word, "es") {
+		switch word[len(word)-3] {
+		case 'l', 'r', 'n', 'd', 'j':
+			return strings.TrimSuffix(word, "es")
+		}
+	}
+
+	return strings.TrimSuffix(word, "s")
+}
+
+// Vector represents the TF-IDF weight of each token of a document
+type Vector map[string]float64
+
+// Corpus represents the document frequencies of the tokens of a collection of documents
+type Corpus struct {
+	documents   int
+	frequencies map[string]int
+}
+
+// NewCorpus returns the corpus for the tokenised documents
+func NewCorpus(documents [][]string) *Corpus {
+	corpus := &Corpus{
+		documents:   len(documents),
+		frequencies: map[string]int{},
+	}
+
+	for _, tokens := range documents {
+		seen := map[string]bool{}
+		for _, token := range tokens {
+			if !seen[token] {
+				corpus.frequencies[token]++
+				seen[token] = true
+			}
+		}
+	}
+
+	return corpus
+}
+
+// Vector returns the TF-IDF vector of a tokenised document, using a smoothed inverse
+// document frequency
+func (c *Corpus) Vector(tokens []string) Vector {
+	vector := Vector{}
+	for _, token := range tokens {
+		vector[token]++
+	}
+
+	for token, tf := range vector {
+		idf := math.Log(float64(1+c.documents)/float64(1+c.frequencies[token])) + 1
+		vector[token] = tf * idf
+	}
+
+	return vector
+}
+
+// Cosine returns the cosine similarity of two vectors, from 0 to 1
+func Cosine(a Vector, b Vector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	dot := 0.0
+	for token, weight := range a {
+		dot += weight * b[token]
+	}
+	if dot == 0 {
+		return 0
+	}
+
+	return dot / (a.norm() * b.norm())
+}
+
+func (v Vector) norm() float64 {
+	sum := 0.0
+	for _, weight := range v {
+		sum += weight * weight
+	}
+
+	return math.Sqrt(sum)
+}
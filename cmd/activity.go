@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mdelapenya/cansino/activity"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var heatmapParam bool
+
+func init() {
+	addReportFlags(activityCmd)
+	addHolidaysFlag(activityCmd)
+	activityCmd.Flags().BoolVarP(&heatmapParam, "heatmap", "m", false, "Reports the events per weekday and hour")
+
+	rootCmd.AddCommand(activityCmd)
+}
+
+var activityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Reports the workload of the owners",
+	Long:  "Reports, per owner, the stored events per day and week, their earliest and latest times, the activity in weekends and holidays, and the days without public agenda",
+	Run: func(cmd *cobra.Command, args []string) {
+		calendars := holidayCalendars()
+		isHoliday := func(region string, day time.Time) bool {
+			_, ok := calendars(region).Holiday(day)
+			return ok
+		}
+
+		query := reportQuery()
+		summaries := activity.Summarize(searchEvents(), query.Since, query.Until, isHoliday)
+
+		var err error
+		if heatmapParam {
+			err = writeHeatmap(summaries)
+		} else {
+			rows := [][]string{}
+			for _, summary := range summaries {
+				rows = append(rows, []string{
+					summary.Owner, summary.Region,
+					summary.From.Format("2006-01-02"), summary.To.Format("2006-01-02"),
+					strconv.Itoa(summary.Days), strconv.Itoa(summary.Events),
+					fmt.Sprintf("%.2f", summary.EventsPerDay), fmt.Sprintf("%.2f", summary.EventsPerWeek),
+					summary.Earliest, summary.Latest,
+					strconv.Itoa(summary.WeekendEvents), strconv.Itoa(summary.HolidayEvents),
+					strconv.Itoa(summary.EmptyDays),
+				})
+			}
+
+			header := []string{"OWNER", "REGION", "FROM", "TO", "DAYS", "EVENTS", "PER DAY", "PER WEEK", "EARLIEST", "LATEST", "WEEKEND", "HOLIDAYS", "EMPTY DAYS"}
+			err = writeReport(os.Stdout, formatParam, header, rows, summaries)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
+
+// writeHeatmap writes the events of each owner per weekday and hour
+func writeHeatmap(summaries []activity.Summary) error {
+	weekdays := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+	header := []string{"OWNER", "REGION", "WEEKDAY"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("%02d", hour))
+	}
+
+	type heatmap struct {
+		Owner   string     `json:"owner"`
+		Region  string     `json:"region"`
+		Heatmap [7][24]int `json:"heatmap"`
+	}
+
+	heatmaps := []heatmap{}
+	rows := [][]string{}
+	for _, summary := range summaries {
+		heatmaps = append(heatmaps, heatmap{Owner: summary.Owner, Region: summary.Region, Heatmap: summary.Heatmap})
+
+		for weekday, hours := range summary.Heatmap {
+			row := []string{summary.Owner, summary.Region, weekdays[weekday]}
+			for _, count := range hours {
+				row = append(row, strconv.Itoa(count))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return writeReport(os.Stdout, formatParam, header, rows, heatmaps)
+}
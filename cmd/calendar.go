@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mdelapenya/cansino/holidays"
+	"github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/regions"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var holidaysParam string
+
+func init() {
+	addReportFlags(calendarCmd)
+	addHolidaysFlag(calendarCmd)
+
+	rootCmd.AddCommand(calendarCmd)
+}
+
+// addHolidaysFlag adds the flag used to override the built-in holidays
+func addHolidaysFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&holidaysParam, "holidays", "H", "", "Sets the ICS or CSV file with extra holidays")
+}
+
+// loadHolidays adds the holidays of the file flag, if set, to the calendar of the region
+func loadHolidays(region *models.Region) {
+	if holidaysParam == "" {
+		return
+	}
+
+	err := region.Holidays.Load(holidaysParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"holidays": holidaysParam,
+			"region":   region.Name,
+			"error":    err,
+		}).Fatal("Cannot load the holidays")
+	}
+}
+
+// holidayCalendars returns a function returning the holiday calendar of a region, with
+// the holidays of the file flag. Unsupported regions only have the national holidays
+func holidayCalendars() func(region string) *holidays.Calendar {
+	calendars := map[string]*holidays.Calendar{}
+
+	return func(name string) *holidays.Calendar {
+		if calendar, ok := calendars[name]; ok {
+			return calendar
+		}
+
+		region, err := regions.RegionFactory(name)
+		if err != nil {
+			region = &models.Region{Name: name, Holidays: holidays.NewCalendar(name)}
+		}
+		loadHolidays(region)
+
+		calendars[name] = region.Holidays
+		return region.Holidays
+	}
+}
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Lists the holidays and the days with and without events",
+	Long:  "Lists, per region and day, if the day was a holiday, had stored events, or had none",
+	Run: func(cmd *cobra.Command, args []string) {
+		regionNames := availableRegionNames
+		if regionParam != "all" {
+			regionNames = []string{regionParam}
+		}
+
+		query := reportQuery()
+		events := searchEvents()
+
+		from, to := query.Since, query.Until
+		counts := map[string]map[string]int{}
+		for _, event := range events {
+			if from.IsZero() || (query.Since.IsZero() && event.Date.Before(from)) {
+				from = event.Date
+			}
+			if to.IsZero() || (query.Until.IsZero() && event.Date.After(to)) {
+				to = event.Date
+			}
+
+			if counts[event.Region] == nil {
+				counts[event.Region] = map[string]int{}
+			}
+			counts[event.Region][event.Date.Format("2006-01-02")]++
+		}
+		if from.IsZero() {
+			from = time.Now()
+		}
+		if to.IsZero() {
+			to = time.Now()
+		}
+
+		type day struct {
+			Region  string            `json:"region"`
+			Date    string            `json:"date"`
+			Status  string            `json:"status"`
+			Events  int               `json:"events"`
+			Holiday *holidays.Holiday `json:"holiday,omitempty"`
+		}
+
+		calendars := holidayCalendars()
+		days := []day{}
+		rows := [][]string{}
+		for _, regionName := range regionNames {
+			calendar := calendars(regionName)
+
+			start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+			end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+			for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+				d := day{
+					Region: regionName,
+					Date:   date.Format("2006-01-02"),
+					Status: "none",
+					Events: counts[regionName][date.Format("2006-01-02")],
+				}
+				if d.Events > 0 {
+					d.Status = "events"
+				}
+
+				name, scope := "", ""
+				if holiday, ok := calendar.Holiday(date); ok {
+					d.Status = "holiday"
+					d.Holiday = &holiday
+					name, scope = holiday.Name, holiday.Scope
+				}
+
+				days = append(days, d)
+				rows = append(rows, []string{
+					d.Region, d.Date, date.Weekday().String(), d.Status, strconv.Itoa(d.Events), name, scope,
+				})
+			}
+		}
+
+		header := []string{"REGION", "DATE", "WEEKDAY", "STATUS", "EVENTS", "HOLIDAY", "SCOPE"}
+		err := writeReport(os.Stdout, formatParam, header, rows, days)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mdelapenya/cansino/consistency"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var durationParam time.Duration
+var speedParam float64
+
+func init() {
+	addReportFlags(consistencyCmd)
+	addGeoFlags(consistencyCmd)
+	consistencyCmd.Flags().DurationVarP(&durationParam, "duration", "d", time.Hour, "Sets the estimated duration of the events")
+	consistencyCmd.Flags().Float64VarP(&speedParam, "speed", "v", 80, "Sets the travel speed between locations, in km/h")
+
+	rootCmd.AddCommand(consistencyCmd)
+}
+
+var consistencyCmd = &cobra.Command{
+	Use:   "consistency",
+	Short: "Checks the agendas for impossible events",
+	Long:  "Reports the stored events of each owner overlapping in time, or too far apart to travel between them in the time in between",
+	Run: func(cmd *cobra.Command, args []string) {
+		if speedParam <= 0 {
+			log.WithFields(log.Fields{
+				"speed": speedParam,
+			}).Fatal("The travel speed must be positive")
+		}
+
+		anomalies := consistency.Check(searchEvents(), newGeocoder(), durationParam, speedParam)
+
+		rows := [][]string{}
+		for _, anomaly := range anomalies {
+			rows = append(rows, []string{
+				anomaly.Kind, anomaly.Owner, anomaly.Region,
+				anomaly.First.Date.Format("2006-01-02 15:04"), anomaly.Second.Date.Format("2006-01-02 15:04"),
+				anomaly.First.OriginalLocation, anomaly.Second.OriginalLocation,
+				strconv.Itoa(anomaly.Gap), fmt.Sprintf("%.1f", anomaly.Distance), strconv.Itoa(anomaly.Required),
+			})
+		}
+
+		header := []string{"KIND", "OWNER", "REGION", "FIRST", "SECOND", "FIRST LOCATION", "SECOND LOCATION", "GAP (MIN)", "DISTANCE (KM)", "REQUIRED (MIN)"}
+		err := writeReport(os.Stdout, formatParam, header, rows, anomalies)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mdelapenya/cansino/entities"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var entityParam string
+var kindParam string
+var overridesParam string
+var registryParam string
+var similarityParam float64
+
+func init() {
+	addReportFlags(entitiesClusterCmd)
+	addEntitiesFlags(entitiesClusterCmd)
+
+	addReportFlags(entitiesReportCmd)
+	addEntitiesFlags(entitiesReportCmd)
+	entitiesReportCmd.Flags().StringVarP(&entityParam, "entity", "E", "", "Sets the name or ID of the entity")
+	entitiesReportCmd.Flags().StringVarP(&kindParam, "kind", "k", "all", "Sets the kind of the entities: person, organization or all")
+
+	entitiesCmd.AddCommand(entitiesClusterCmd)
+	entitiesCmd.AddCommand(entitiesReportCmd)
+	rootCmd.AddCommand(entitiesCmd)
+}
+
+// addEntitiesFlags adds the flags used to resolve entities
+func addEntitiesFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&registryParam, "registry", "e", "entities.json", "Sets the JSON file with the registry of entities")
+	cmd.Flags().StringVarP(&overridesParam, "overrides", "m", "", "Sets the JSON file with the manual merges and splits of entities")
+	cmd.Flags().Float64VarP(&similarityParam, "similarity", "S", 0.9, "Sets the minimum similarity for two names to be the same entity")
+}
+
+var entitiesCmd = &cobra.Command{
+	Use:   "entities",
+	Short: "Resolves attendees and organizations into entities",
+	Long:  "Clusters the attendees and organizations of the stored events into canonical entities with stable IDs",
+}
+
+var entitiesClusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Builds the registry of entities",
+	Long:  "Clusters the attendees and organizations of the stored events into the registry of entities, keeping the IDs of the previous registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		previous, err := entities.LoadRegistry(registryParam)
+		if err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"registry": registryParam,
+				"error":    err,
+			}).Fatal("Cannot load the registry of entities")
+		}
+
+		mentions := entities.Mentions(searchEvents())
+		registry := entities.Cluster(mentions, previous, loadOverrides(), similarityParam)
+
+		err = entities.SaveRegistry(registryParam, registry)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"registry": registryParam,
+				"error":    err,
+			}).Fatal("Cannot store the registry of entities")
+		}
+
+		log.WithFields(log.Fields{
+			"mentions": len(mentions),
+			"entities": len(registry),
+			"registry": registryParam,
+		}).Info("Entities clustered")
+	},
+}
+
+var entitiesReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Reports the meetings with each entity",
+	Long:  "Reports, per entity and owner, how many stored events they shared, and when",
+	Run: func(cmd *cobra.Command, args []string) {
+		resolver := newResolver()
+
+		entityID := ""
+		if entityParam != "" {
+			kind := kindParam
+			if kind == "all" {
+				kind = entities.PersonEntity
+			}
+
+			entity, ok := resolver.Entity(kind, entityParam)
+			if !ok && kindParam == "all" {
+				entity, ok = resolver.Entity(entities.OrganizationEntity, entityParam)
+			}
+			if !ok {
+				log.WithFields(log.Fields{
+					"entity": entityParam,
+				}).Fatal("Entity not found")
+			}
+			entityID = entity.ID
+		}
+
+		meetings := []entities.Meetings{}
+		rows := [][]string{}
+		for _, m := range resolver.Meetings(searchEvents()) {
+			if entityID != "" && m.EntityID != entityID {
+				continue
+			}
+			if kindParam != "all" && m.Kind != kindParam {
+				continue
+			}
+
+			meetings = append(meetings, m)
+			rows = append(rows, []string{
+				m.EntityID, m.Kind, m.Name, m.Owner, strconv.Itoa(m.Events),
+				m.First.Format("2006-01-02"), m.Last.Format("2006-01-02"),
+			})
+		}
+
+		header := []string{"ID", "KIND", "NAME", "OWNER", "EVENTS", "FIRST", "LAST"}
+		err := writeReport(os.Stdout, formatParam, header, rows, meetings)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
+
+// loadOverrides loads the manual merges and splits of entities, if set
+func loadOverrides() entities.Overrides {
+	if overridesParam == "" {
+		return entities.Overrides{}
+	}
+
+	overrides, err := entities.LoadOverrides(overridesParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"overrides": overridesParam,
+			"error":     err,
+		}).Fatal("Cannot load the overrides of entities")
+	}
+
+	return overrides
+}
+
+// newResolver returns a resolver for the registry and overrides of entities. A missing
+// registry is not an error: names resolve to IDs derived from them
+func newResolver() *entities.Resolver {
+	registry, err := entities.LoadRegistry(registryParam)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"registry": registryParam,
+				"error":    err,
+			}).Fatal("Cannot load the registry of entities")
+		}
+
+		log.WithFields(log.Fields{
+			"registry": registryParam,
+		}).Debug("Registry of entities not found")
+	}
+
+	return entities.NewResolver(registry, loadOverrides(), similarityParam)
+}
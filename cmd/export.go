@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mdelapenya/cansino/geo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exportFormatParam string
+
+func init() {
+	addFilterFlags(exportCmd)
+	addGeoFlags(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFormatParam, "format", "f", "geojson", "Sets the output format: geojson or kml")
+	exportCmd.Flags().StringVarP(&outputParam, "output", "O", "", "Sets the output file. If not set, the standard output is used")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the events to a map",
+	Long:  "Exports the stored events whose locations are found in the gazetteer, as GeoJSON or KML, for QGIS, uMap or Kibana Maps",
+	Run: func(cmd *cobra.Command, args []string) {
+		events := searchEvents()
+		located := newGeocoder().Locate(events)
+
+		output := createOutput(outputParam)
+		defer closeOutput(output)
+
+		var err error
+		switch exportFormatParam {
+		case "geojson":
+			err = geo.EventFeatures(located).Write(output)
+		case "kml":
+			err = geo.WriteKML(output, "cansino", located)
+		default:
+			err = fmt.Errorf("unsupported format %s. Please use geojson or kml", exportFormatParam)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"format": exportFormatParam,
+				"error":  err,
+			}).Fatal("Cannot export the events")
+		}
+
+		if len(located) < len(events) {
+			log.WithFields(log.Fields{
+				"events":   len(events),
+				"notFound": len(events) - len(located),
+			}).Warn("Events whose locations are not found in the gazetteer, not exported")
+		}
+
+		log.WithFields(log.Fields{
+			"events":  len(events),
+			"located": len(located),
+		}).Info("Events exported")
+	},
+}
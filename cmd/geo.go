@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/mdelapenya/cansino/geo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var municipalitiesParam string
+var venuesParam string
+
+// addGeoFlags adds the flags used to extend the bundled gazetteer of municipalities
+func addGeoFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&municipalitiesParam, "municipalities", "", "", "Sets the CSV file with extra municipalities (ine,name,lat,lon), as the IGN MUNICIPIOS.csv")
+	cmd.Flags().StringVarP(&venuesParam, "venues", "", "", "Sets the JSON file with extra venues")
+}
+
+// newGeocoder returns the bundled gazetteer, extended with the municipalities and venues files
+func newGeocoder() *geo.Gazetteer {
+	gazetteer := geo.NewGazetteer()
+
+	if municipalitiesParam != "" {
+		err := gazetteer.LoadMunicipalities(municipalitiesParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"municipalities": municipalitiesParam,
+				"error":          err,
+			}).Fatal("Cannot load the municipalities")
+		}
+	} else {
+		log.Warn("The bundled gazetteer only includes the main municipalities, so the locations in other ones are not geocoded. Please set the --municipalities flag to the whole INE list")
+	}
+
+	if venuesParam != "" {
+		err := gazetteer.LoadVenues(venuesParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"venues": venuesParam,
+				"error":  err,
+			}).Fatal("Cannot load the venues")
+		}
+	}
+
+	return gazetteer
+}
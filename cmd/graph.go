@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mdelapenya/cansino/graph"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var graphFormatParam string
+var outputParam string
+
+func init() {
+	addFilterFlags(graphCmd)
+	addEntitiesFlags(graphCmd)
+	graphCmd.Flags().StringVarP(&graphFormatParam, "format", "f", "graphml", "Sets the output format: graphml, gexf or csv")
+	graphCmd.Flags().StringVarP(&outputParam, "output", "O", "", "Sets the output file. If not set, the standard output is used")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Exports the meeting network",
+	Long:  "Exports the co-occurrence network of owners, attendees and organizations of the stored events, for Gephi",
+	Run: func(cmd *cobra.Command, args []string) {
+		g := graph.Build(searchEvents(), newResolver())
+
+		output := createOutput(outputParam)
+		defer closeOutput(output)
+
+		var err error
+		switch graphFormatParam {
+		case "graphml":
+			err = g.WriteGraphML(output)
+		case "gexf":
+			err = g.WriteGEXF(output)
+		case "csv":
+			err = g.WriteCSV(output)
+		default:
+			err = fmt.Errorf("unsupported format %s. Please use graphml, gexf or csv", graphFormatParam)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"format": graphFormatParam,
+				"error":  err,
+			}).Fatal("Cannot export the graph")
+		}
+
+		log.WithFields(log.Fields{
+			"nodes": len(g.Nodes),
+			"edges": len(g.Edges),
+		}).Info("Graph exported")
+	},
+}
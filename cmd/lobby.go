@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mdelapenya/cansino/lobbies"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var allMeetingsParam bool
+var lobbiesParam string
+
+func init() {
+	addReportFlags(lobbyReportCmd)
+	lobbyReportCmd.Flags().StringVarP(&lobbiesParam, "lobbies", "l", "", "Sets the CSV or JSON file with the register of lobbies")
+	lobbyReportCmd.Flags().Float64VarP(&similarityParam, "similarity", "S", 0.9, "Sets the minimum similarity for an organization to match a lobby")
+	lobbyReportCmd.Flags().BoolVarP(&allMeetingsParam, "all", "a", false, "Includes the meetings with registered lobbies")
+	lobbyReportCmd.MarkFlagRequired("lobbies")
+
+	lobbyCmd.AddCommand(lobbyReportCmd)
+	rootCmd.AddCommand(lobbyCmd)
+}
+
+var lobbyCmd = &cobra.Command{
+	Use:   "lobby",
+	Short: "Cross-references meetings with a register of lobbies",
+	Long:  "Links the organizations of the stored events to a register of interest groups",
+}
+
+var lobbyReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Reports the meetings with unregistered organizations",
+	Long:  "Reports the stored events with organizations not found in the register of lobbies",
+	Run: func(cmd *cobra.Command, args []string) {
+		meetings := []lobbies.Meeting{}
+		rows := [][]string{}
+		for _, meeting := range newRegister().Meetings(searchEvents(), similarityParam) {
+			if meeting.Registered && !allMeetingsParam {
+				continue
+			}
+
+			lobby := ""
+			if meeting.Lobby != nil {
+				lobby = meeting.Lobby.Name
+			}
+
+			meetings = append(meetings, meeting)
+			rows = append(rows, []string{
+				meeting.Date.Format("2006-01-02 15:04"), meeting.Owner, meeting.Region,
+				meeting.Organization, meeting.Type, strconv.FormatBool(meeting.Registered), lobby,
+				meeting.EventID,
+			})
+		}
+
+		header := []string{"DATE", "OWNER", "REGION", "ORGANIZATION", "TYPE", "REGISTERED", "LOBBY", "EVENT"}
+		err := writeReport(os.Stdout, formatParam, header, rows, meetings)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
+
+// newRegister returns the register of lobbies of the file flag, or nil if not set
+func newRegister() *lobbies.Register {
+	if lobbiesParam == "" {
+		return nil
+	}
+
+	register, err := lobbies.Load(lobbiesParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"lobbies": lobbiesParam,
+			"error":   err,
+		}).Fatal("Cannot load the register of lobbies")
+	}
+
+	return register
+}
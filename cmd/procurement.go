@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/mdelapenya/cansino/procurement"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var awardsParam string
+var awardsRegionParam string
+var daysParam int
+
+func init() {
+	addReportFlags(procurementReportCmd)
+	procurementReportCmd.Flags().StringVarP(&awardsParam, "awards", "A", "", "Sets the CSV file with the contract awards")
+	procurementReportCmd.Flags().StringVarP(&awardsRegionParam, "awards-region", "R", "", "Sets the region of the awards not including it")
+	procurementReportCmd.Flags().IntVarP(&daysParam, "days", "d", 30, "Sets the maximum days between a meeting and an award")
+	procurementReportCmd.Flags().Float64VarP(&similarityParam, "similarity", "S", 0.9, "Sets the minimum similarity for an organization to match an awardee")
+	procurementReportCmd.MarkFlagRequired("awards")
+
+	procurementCmd.AddCommand(procurementReportCmd)
+	rootCmd.AddCommand(procurementCmd)
+}
+
+var procurementCmd = &cobra.Command{
+	Use:   "procurement",
+	Short: "Cross-references meetings with public contract awards",
+	Long:  "Finds the organizations that met an owner around the days they received a contract from the same administration",
+}
+
+var procurementReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Reports the meetings around contract awards",
+	Long:  "Reports, per organization, a timeline of the contracts it was awarded and the stored events it took part in within the days before or after",
+	Run: func(cmd *cobra.Command, args []string) {
+		awards, err := procurement.Load(awardsParam, awardsRegionParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"awards": awardsParam,
+				"error":  err,
+			}).Fatal("Cannot load the contract awards")
+		}
+
+		timelines := procurement.Timelines(awards, searchEvents(), daysParam, similarityParam)
+
+		rows := [][]string{}
+		for _, timeline := range timelines {
+			for _, entry := range timeline.Entries {
+				days, amount := "", ""
+				if entry.Kind == procurement.MeetingEntry {
+					days = strconv.Itoa(entry.Days)
+				} else {
+					amount = fmt.Sprintf("%.2f", entry.Amount)
+				}
+
+				rows = append(rows, []string{
+					timeline.Organization, entry.Date.Format("2006-01-02"), entry.Kind, entry.Owner,
+					entry.Region, days, amount, truncate(entry.Description, 60), entry.Link,
+				})
+			}
+		}
+
+		header := []string{"ORGANIZATION", "DATE", "KIND", "OWNER", "REGION", "DAYS", "AMOUNT", "DESCRIPTION", "LINK"}
+		err = writeReport(os.Stdout, formatParam, header, rows, timelines)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/mdelapenya/cansino/promises"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var promisesFileParam string
+var promisesOutputParam string
+var thresholdParam float64
+
+func init() {
+	promisesImportCmd.Flags().StringVarP(&promisesFileParam, "file", "F", "", "Sets the Markdown or CSV file with the promises")
+	promisesImportCmd.Flags().StringVarP(&promisesOutputParam, "output", "O", "promises.json", "Sets the JSON file where to store the promises")
+	promisesImportCmd.MarkFlagRequired("file")
+
+	addReportFlags(promisesReportCmd)
+	promisesReportCmd.Flags().StringVarP(&promisesFileParam, "file", "F", "", "Sets the Markdown, CSV or JSON file with the promises")
+	promisesReportCmd.Flags().Float64VarP(&thresholdParam, "threshold", "T", 0.2, "Sets the minimum similarity for an event to relate to a promise")
+	promisesReportCmd.MarkFlagRequired("file")
+
+	promisesCmd.AddCommand(promisesImportCmd)
+	promisesCmd.AddCommand(promisesReportCmd)
+	rootCmd.AddCommand(promisesCmd)
+}
+
+var promisesCmd = &cobra.Command{
+	Use:   "promises",
+	Short: "Matches agendas against political promises",
+	Long:  "Imports a corpus of political promises and matches the stored events against them",
+}
+
+var promisesImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Imports a corpus of promises",
+	Long:  "Imports a corpus of promises from Markdown or CSV, storing it as JSON",
+	Run: func(cmd *cobra.Command, args []string) {
+		corpus := loadPromises()
+
+		bytes, err := json.MarshalIndent(corpus, "", "  ")
+		if err == nil {
+			err = ioutil.WriteFile(promisesOutputParam, bytes, 0644)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"output": promisesOutputParam,
+				"error":  err,
+			}).Fatal("Cannot store the promises")
+		}
+
+		log.WithFields(log.Fields{
+			"promises": len(corpus),
+			"output":   promisesOutputParam,
+		}).Info("Promises imported")
+	},
+}
+
+var promisesReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Reports the activity related to each promise",
+	Long:  "Reports, per promise, how many stored events relate to it and when, and the promises with no matching activity",
+	Run: func(cmd *cobra.Command, args []string) {
+		activities := promises.Report(loadPromises(), searchEvents(), thresholdParam)
+
+		rows := [][]string{}
+		withoutActivity := []promises.Promise{}
+		for _, activity := range activities {
+			first, last := "", ""
+			if activity.Events > 0 {
+				first = activity.First.Format("2006-01-02")
+				last = activity.Last.Format("2006-01-02")
+			} else {
+				withoutActivity = append(withoutActivity, activity.Promise)
+			}
+
+			rows = append(rows, []string{
+				activity.Promise.ID, truncate(activity.Promise.Text, 80),
+				strconv.Itoa(activity.Events), first, last,
+			})
+		}
+
+		header := []string{"ID", "PROMISE", "EVENTS", "FIRST", "LAST"}
+		err := writeReport(os.Stdout, formatParam, header, rows, activities)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+
+		if formatParam == "table" && len(withoutActivity) > 0 {
+			fmt.Printf("\n%d of %d promises without matching activity:\n", len(withoutActivity), len(activities))
+			for _, promise := range withoutActivity {
+				fmt.Printf("- [%s] %s\n", promise.ID, promise.Text)
+			}
+		}
+	},
+}
+
+// loadPromises loads the promises from the file flag
+func loadPromises() []promises.Promise {
+	corpus, err := promises.Load(promisesFileParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  promisesFileParam,
+			"error": err,
+		}).Fatal("Cannot load the promises")
+	}
+
+	return corpus
+}
+
+// truncate shortens a text to a maximum number of characters
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mdelapenya/cansino/indexers"
+	"github.com/mdelapenya/cansino/models"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var formatParam string
+var intervalParam string
+var ownerParam string
+var sinceParam string
+var untilParam string
+
+// addFilterFlags adds the flags used to filter the stored events
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&regionParam, "region", "r", "all", "Sets the region of the events")
+	cmd.Flags().StringVarP(&ownerParam, "owner", "o", "", "Sets the owner of the events")
+	cmd.Flags().StringVarP(&sinceParam, "since", "s", "", "Sets the first day of the events (yyyy-MM-dd)")
+	cmd.Flags().StringVarP(&untilParam, "until", "u", "", "Sets the last day of the events (yyyy-MM-dd)")
+}
+
+// addReportFlags adds the flags used by the commands reporting on stored events
+func addReportFlags(cmd *cobra.Command) {
+	addFilterFlags(cmd)
+	cmd.Flags().StringVarP(&formatParam, "format", "f", "table", "Sets the output format: table, csv or json")
+}
+
+// reportQuery returns the query to retrieve the stored events, built from the report flags
+func reportQuery() indexers.EventsQuery {
+	query := indexers.EventsQuery{
+		Owner: ownerParam,
+	}
+
+	if regionParam != "all" {
+		query.Region = regionParam
+	}
+	if sinceParam != "" {
+		query.Since = toDate(sinceParam)
+	}
+	if untilParam != "" {
+		// include the whole last day
+		query.Until = toDate(untilParam).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return query
+}
+
+// searchEvents retrieves the stored events matching the report flags
+func searchEvents() []models.AgendaEvent {
+	query := reportQuery()
+
+	searcher, _ := indexers.GetSearcher("elasticsearch")
+	events, err := searcher.Search(context.Background(), query)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"query": query,
+			"error": err,
+		}).Fatal("Cannot retrieve the stored events")
+	}
+
+	return events
+}
+
+// createOutput returns the file where to write an export, or the standard output if
+// the path is empty
+func createOutput(path string) *os.File {
+	if path == "" {
+		return os.Stdout
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"output": path,
+			"error":  err,
+		}).Fatal("Cannot create the output file")
+	}
+
+	return file
+}
+
+// closeOutput closes the output file, unless it is the standard output
+func closeOutput(output *os.File) {
+	if output != os.Stdout {
+		output.Close()
+	}
+}
+
+// periodOf returns a function calculating the period of a date for the interval
+func periodOf(interval string) (func(time.Time) string, error) {
+	switch interval {
+	case "day":
+		return func(t time.Time) string { return t.Format("2006-01-02") }, nil
+	case "week":
+		return func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%04d-W%02d", year, week)
+		}, nil
+	case "month":
+		return func(t time.Time) string { return t.Format("2006-01") }, nil
+	case "year":
+		return func(t time.Time) string { return t.Format("2006") }, nil
+	}
+
+	return nil, fmt.Errorf("unsupported interval %s. Please use day, week, month or year", interval)
+}
+
+// writeReport writes a report in the format: rows for tables and CSV, and data for JSON
+func writeReport(w io.Writer, format string, header []string, rows [][]string, data interface{}) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		err := cw.Write(header)
+		if err != nil {
+			return err
+		}
+		err = cw.WriteAll(rows)
+		if err != nil {
+			return err
+		}
+		return cw.Error()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+
+	return fmt.Errorf("unsupported format %s. Please use table, csv or json", format)
+}
@@ -2,17 +2,32 @@ package cmd
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/entities"
+	"github.com/mdelapenya/cansino/geo"
 	"github.com/mdelapenya/cansino/indexers"
+	"github.com/mdelapenya/cansino/lobbies"
 	"github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/people"
 	"github.com/mdelapenya/cansino/regions"
+	"github.com/mdelapenya/cansino/topics"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var dateParam string
+var delayParam time.Duration
+var detailsParam bool
+var discoverOwnersParam bool
+var gazetteerParam string
+var ownerContainsParam bool
+var ownersParam string
+var peopleParam string
 var regionParam string
+var sourcesParam string
 
 var availableRegionNames = []string{
 	"Castilla-La Mancha", "Castilla-León", "Extremadura", "Madrid",
@@ -20,11 +35,59 @@ var availableRegionNames = []string{
 
 var availableRegions = map[string]*models.Region{}
 
+// extractor finds the people and organizations mentioned in the events before indexing them
+var extractor *entities.Extractor
+
+// geocoder geocodes the locations of the events before indexing them
+var geocoder *geo.Gazetteer
+
+// officials normalizes the owners of the events, attributing them to the people holding their offices, before indexing them
+var officials *people.Registry
+
+// register links the organizations of the events to registered lobbies before indexing them
+var register *lobbies.Register
+
+// resolver resolves the people and organizations of the events into entities before indexing them
+var resolver *entities.Resolver
+
+// tagger tags the events with policy areas before indexing them
+var tagger *topics.Tagger
+
 func init() {
 	getCmd.Flags().StringVarP(&dateParam, "since", "s", "Today", "Sets the date since to be run (yyyy-MM-dd)")
 	getCmd.Flags().StringVarP(&regionParam, "region", "r", "all", "Sets the region to be run")
+	getCmd.Flags().StringVarP(&taxonomyParam, "taxonomy", "t", "", "Sets the JSON file with the terms of each topic")
+	getCmd.Flags().StringVarP(&gazetteerParam, "gazetteer", "g", "", "Sets the JSON file with extra positions and organizations to find in the events")
+	addEntitiesFlags(getCmd)
+	getCmd.Flags().StringVarP(&lobbiesParam, "lobbies", "l", "", "Sets the CSV or JSON file with the register of lobbies")
+	addGeoFlags(getCmd)
+	addHolidaysFlag(getCmd)
+	getCmd.Flags().StringVarP(&ownerParam, "owner", "o", "", "Sets the owner, or the ID of its agenda, to be run")
+	getCmd.Flags().BoolVarP(&ownerContainsParam, "owner-contains", "", false, "Runs the owners whose name contains the owner, instead of the ones named as it")
+	getCmd.Flags().StringVarP(&ownersParam, "owners", "", "", "Sets the JSON file with extra owners of each region")
+	getCmd.Flags().BoolVarP(&discoverOwnersParam, "discover-owners", "", false, "Adds the owners listed by the source of each region, in the regions listing them")
+	getCmd.Flags().StringVarP(&sourcesParam, "sources", "", "", "Sets the JSON file with the sources of extra regions")
+	getCmd.Flags().StringVarP(&peopleParam, "people", "", "", "Sets the JSON file with extra people and the offices they held")
+	getCmd.Flags().BoolVarP(&detailsParam, "details", "D", false, "Follows the detail pages of the events, in the regions linking them")
+	getCmd.Flags().DurationVarP(&delayParam, "delay", "", 500*time.Millisecond, "Sets the delay between the requests to the same site")
 
 	chaseCmd.Flags().StringVarP(&regionParam, "region", "r", "all", "Sets the region to be run")
+	chaseCmd.Flags().StringVarP(&taxonomyParam, "taxonomy", "t", "", "Sets the JSON file with the terms of each topic")
+	chaseCmd.Flags().StringVarP(&gazetteerParam, "gazetteer", "g", "", "Sets the JSON file with extra positions and organizations to find in the events")
+	addEntitiesFlags(chaseCmd)
+	chaseCmd.Flags().StringVarP(&lobbiesParam, "lobbies", "l", "", "Sets the CSV or JSON file with the register of lobbies")
+	addGeoFlags(chaseCmd)
+	addHolidaysFlag(chaseCmd)
+	chaseCmd.Flags().StringVarP(&ownerParam, "owner", "o", "", "Sets the owner, or the ID of its agenda, to be run")
+	chaseCmd.Flags().BoolVarP(&ownerContainsParam, "owner-contains", "", false, "Runs the owners whose name contains the owner, instead of the ones named as it")
+	chaseCmd.Flags().StringVarP(&ownersParam, "owners", "", "", "Sets the JSON file with extra owners of each region")
+	chaseCmd.Flags().BoolVarP(&discoverOwnersParam, "discover-owners", "", false, "Adds the owners listed by the source of each region, in the regions listing them")
+	chaseCmd.Flags().StringVarP(&sourcesParam, "sources", "", "", "Sets the JSON file with the sources of extra regions")
+	chaseCmd.Flags().StringVarP(&peopleParam, "people", "", "", "Sets the JSON file with extra people and the offices they held")
+	chaseCmd.Flags().BoolVarP(&detailsParam, "details", "D", false, "Follows the detail pages of the events, in the regions linking them")
+	chaseCmd.Flags().DurationVarP(&delayParam, "delay", "", 500*time.Millisecond, "Sets the delay between the requests to the same site")
+
+	listAgendasCmd.Flags().StringVarP(&sourcesParam, "sources", "", "", "Sets the JSON file with the sources of extra regions")
 
 	rootCmd.AddCommand(chaseCmd)
 	rootCmd.AddCommand(getCmd)
@@ -55,6 +118,8 @@ var chaseCmd = &cobra.Command{
 	Short: "Gets all agendas",
 	Long:  "Performs the scrapping and indexing of all agendas",
 	Run: func(cmd *cobra.Command, args []string) {
+		loadSources()
+
 		regionNames := availableRegionNames
 		if regionParam != "all" {
 			regionNames = []string{regionParam}
@@ -68,9 +133,18 @@ var chaseCmd = &cobra.Command{
 					"region": regionName,
 				}).Fatal("Cannot initialise regions")
 			}
+			loadHolidays(region)
+			loadOwners(region)
 			availableRegions[regionName] = region
 		}
 
+		extractor = newExtractor()
+		geocoder = newGeocoder()
+		officials = newOfficials()
+		register = newRegister()
+		resolver = newResolver()
+		tagger = newTagger()
+
 		for _, region := range availableRegions {
 			err := processRegion(context.Background(), region, region.StartDate.ToDate())
 			if err != nil {
@@ -94,6 +168,8 @@ var getCmd = &cobra.Command{
 			t = toDate(dateParam)
 		}
 
+		loadSources()
+
 		regionNames := availableRegionNames
 		if regionParam != "all" {
 			regionNames = []string{regionParam}
@@ -107,9 +183,18 @@ var getCmd = &cobra.Command{
 					"region": regionName,
 				}).Fatal("Cannot initialise regions")
 			}
+			loadHolidays(region)
+			loadOwners(region)
 			availableRegions[regionName] = region
 		}
 
+		extractor = newExtractor()
+		geocoder = newGeocoder()
+		officials = newOfficials()
+		register = newRegister()
+		resolver = newResolver()
+		tagger = newTagger()
+
 		for _, region := range availableRegions {
 			err := processRegion(context.Background(), region, t)
 			if err != nil {
@@ -127,6 +212,8 @@ var listAgendasCmd = &cobra.Command{
 	Short: "List all agendas",
 	Long:  "List all agendas",
 	Run: func(cmd *cobra.Command, args []string) {
+		loadSources()
+
 		regionNames := availableRegionNames
 
 		for _, regionName := range regionNames {
@@ -155,23 +242,78 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-func processAgenda(ctx context.Context, region *models.Region, day int, month int, year int) error {
+// matchesOwner checks if an owner, or its ID in the source, matches the owner flag: the ID
+// exactly, or the whole name, ignoring case and accents. With the owner-contains flag, the
+// names containing it match too
+func matchesOwner(owner string, id string) bool {
+	if ownerParam == "" || (id != "" && id == ownerParam) {
+		return true
+	}
+
+	name := analysis.Normalize(owner)
+	if ownerContainsParam {
+		return strings.Contains(name, analysis.Normalize(ownerParam))
+	}
+
+	return name == analysis.Normalize(ownerParam)
+}
+
+// processAgenda processes the agenda of an owner from a day until another one, in a single
+// request for the regions fetching several days, recording the holiday of each day, if any.
+// Only the events of the owners matching the owner flag are indexed
+func processAgenda(ctx context.Context, region *models.Region, owner models.Owner, from time.Time, until time.Time) error {
 	log.WithFields(log.Fields{
-		"day":    day,
-		"month":  month,
-		"year":   year,
+		"from":   from.Format("2006-01-02"),
+		"until":  until.Format("2006-01-02"),
 		"region": region.Name,
+		"owner":  owner.Name,
 	}).Info("Processing agenda")
 
-	agenda, err := regions.AgendaFactory(region, day, month, year)
+	agenda, err := regions.AgendaRangeFactory(region, owner, from, until)
 	if err != nil {
 		return err
 	}
 
+	agenda.FollowDetails = detailsParam
+	agenda.Delay = delayParam
 	agenda.Scrap(context.Background())
 
+	for _, dayAgenda := range agenda.SplitDays() {
+		holiday, _ := region.Holidays.Holiday(dayAgenda.Date)
+		dayAgenda.Holiday = holiday.Name
+
+		err := indexAgenda(dayAgenda, owner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexAgenda indexes the events of the agenda of a day of an owner. The events of the
+// owner match the owner flag by its ID too
+func indexAgenda(agenda *models.Agenda, owner models.Owner) error {
 	indexer, _ := indexers.GetIndexer("elasticsearch")
 	for _, event := range agenda.Events {
+		id := ""
+		if event.Owner == owner.Name {
+			id = owner.ID
+		}
+		if !matchesOwner(event.Owner, id) {
+			continue
+		}
+
+		event.Holiday = agenda.Holiday
+		officials.NormalizeEvent(&event)
+		extractor.ExtractEvent(&event)
+		resolver.ResolveEvent(&event)
+		if register != nil {
+			register.LinkEvent(&event, similarityParam)
+		}
+		tagger.TagEvent(&event)
+		geocoder.GeocodeEvent(&event)
+
 		err := indexer.Index(context.Background(), event)
 		if err != nil {
 			log.WithFields(log.Fields{
@@ -190,27 +332,152 @@ func processAgenda(ctx context.Context, region *models.Region, day int, month in
 func processRegion(ctx context.Context, region *models.Region, start time.Time) error {
 	end := time.Now()
 
+	// the days already fetched with the previous ones
+	var next time.Time
 	for rd := regions.RangeDate(start, end); ; {
 		date := rd()
 		if date.IsZero() {
 			break
 		}
 
-		err := processAgenda(context.Background(), region, date.Day(), int(date.Month()), date.Year())
-		if err != nil {
-			return err
+		holiday, isHoliday := region.Holidays.Holiday(date)
+		if isHoliday {
+			log.WithFields(log.Fields{
+				"date":    date.Format("2006-01-02"),
+				"holiday": holiday.Name,
+				"scope":   holiday.Scope,
+				"region":  region.Name,
+			}).Info("Processing a holiday")
+		}
+
+		if date.Before(next) {
+			continue
+		}
+		until := regions.BatchEnd(region.Granularity, date, end)
+		next = until.AddDate(0, 0, 1)
+
+		for _, owner := range region.Owners {
+			// sources publishing the agendas of the whole government are filtered by event
+			if owner.ID != "" && !matchesOwner(owner.Name, owner.ID) {
+				continue
+			}
+
+			err := processAgenda(context.Background(), region, owner, date, until)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// loadSources makes available the regions of the sources file, if set
+func loadSources() {
+	if sourcesParam == "" {
+		return
+	}
+
+	names, err := regions.LoadSources(sourcesParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"sources": sourcesParam,
+			"error":   err,
+		}).Fatal("Cannot load the sources")
+	}
+
+	for _, name := range names {
+		if !contains(availableRegionNames, name) {
+			availableRegionNames = append(availableRegionNames, name)
+		}
+	}
+}
+
+// loadOwners adds the owners listed by the source of the region, if it lists them and the
+// discover-owners flag is set, and the ones of the region in the owners file, if set
+func loadOwners(region *models.Region) {
+	if discoverOwnersParam && region.DiscoverOwners != nil {
+		owners, err := region.DiscoverOwners(delayParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"region": region.Name,
+				"error":  err,
+			}).Warn("Cannot discover the owners of the region. Using the built-in ones")
+		}
+
+		for _, owner := range owners {
+			addOwner(region, owner)
+		}
+	}
+
+	if ownersParam == "" {
+		return
+	}
+
+	owners, err := regions.LoadOwners(ownersParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"owners": ownersParam,
+			"error":  err,
+		}).Fatal("Cannot load the owners")
+	}
+
+	for _, owner := range owners[region.Name] {
+		addOwner(region, owner)
+	}
+}
+
+// addOwner adds an owner to a region, unless its ID is already there
+func addOwner(region *models.Region, owner models.Owner) {
+	for _, existing := range region.Owners {
+		if owner.ID != "" && existing.ID == owner.ID {
+			return
+		}
+	}
+
+	region.Owners = append(region.Owners, owner)
+}
+
+// newExtractor returns an extractor for the built-in gazetteer, extended with the gazetteer file
+func newExtractor() *entities.Extractor {
+	gazetteer := entities.DefaultGazetteer()
+	if gazetteerParam != "" {
+		var err error
+		gazetteer, err = entities.LoadGazetteer(gazetteerParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"gazetteer": gazetteerParam,
+				"error":     err,
+			}).Fatal("Cannot load the gazetteer")
+		}
+	}
+
+	return entities.NewExtractor(gazetteer)
+}
+
+// newOfficials returns the registry of the built-in people, extended with the people file
+func newOfficials() *people.Registry {
+	if peopleParam == "" {
+		return people.DefaultRegistry()
+	}
+
+	registry, err := people.Load(peopleParam)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"people": peopleParam,
+			"error":  err,
+		}).Fatal("Cannot load the people")
+	}
+
+	return registry
+}
+
 func toDate(str string) time.Time {
 	layout := "2006-01-02"
 	parsedDate, err := time.Parse(layout, str)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"date":  dateParam,
+			"date":  str,
 			"error": err,
 		}).Fatal("Wrong date format. Please use yyyy-MM-dd")
 	}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mdelapenya/cansino/geo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var ruralPopulationParam int
+
+func init() {
+	addFilterFlags(territoryCmd)
+	addGeoFlags(territoryCmd)
+	territoryCmd.Flags().StringVarP(&formatParam, "format", "f", "table", "Sets the output format: table, csv, json or geojson")
+	territoryCmd.Flags().StringVarP(&intervalParam, "interval", "i", "month", "Sets the period of the report: day, week, month or year")
+	territoryCmd.Flags().IntVarP(&ruralPopulationParam, "rural-population", "p", 5000, "Sets the population under which a municipality is rural")
+
+	rootCmd.AddCommand(territoryCmd)
+}
+
+var territoryCmd = &cobra.Command{
+	Use:   "territory",
+	Short: "Reports the territorial balance of the events",
+	Long:  "Reports, per owner and period, the share of stored events in the capital of the region and elsewhere, and per province. With the whole INE list of municipalities, set with the --municipalities flag, the events in rural and urban municipalities and the municipalities never visited are reported too",
+	Run: func(cmd *cobra.Command, args []string) {
+		period, err := periodOf(intervalParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Wrong interval")
+		}
+
+		territories := newGeocoder().Territories(searchEvents(), period, ruralPopulationParam)
+
+		// the rural and urban events and the municipalities not visited are only reported
+		// when the whole INE list of the regions is loaded
+		complete := len(territories) > 0
+		for _, territory := range territories {
+			complete = complete && territory.Complete
+		}
+
+		if formatParam == "geojson" {
+			err = geo.TerritoryFeatures(territories).Write(os.Stdout)
+		} else {
+			rows := [][]string{}
+			for _, territory := range territories {
+				row := []string{
+					territory.Owner, territory.Region, territory.Period,
+					strconv.Itoa(territory.Events), strconv.Itoa(territory.Located),
+					share(territory.Capital, territory.Located), share(territory.Elsewhere, territory.Located),
+					provinceCounts(territory.Provinces),
+				}
+				if complete {
+					row = append(row, strconv.Itoa(territory.Rural), strconv.Itoa(territory.Urban), strconv.Itoa(len(territory.NotVisited)))
+				}
+
+				rows = append(rows, row)
+			}
+
+			header := []string{"OWNER", "REGION", "PERIOD", "EVENTS", "LOCATED", "CAPITAL", "ELSEWHERE", "PROVINCES"}
+			if complete {
+				header = append(header, "RURAL", "URBAN", "NOT VISITED")
+			}
+			err = writeReport(os.Stdout, formatParam, header, rows, territories)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
+
+// share returns the share of a count in a total, formatted
+func share(count int, total int) string {
+	if total == 0 {
+		return "0.00"
+	}
+
+	return fmt.Sprintf("%.2f", float64(count)/float64(total))
+}
+
+// provinceCounts formats the events per province, most visited first
+func provinceCounts(provinces map[string]int) string {
+	names := []string{}
+	for name := range provinces {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if provinces[names[i]] != provinces[names[j]] {
+			return provinces[names[i]] > provinces[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	counts := []string{}
+	for _, name := range names {
+		counts = append(counts, fmt.Sprintf("%s:%d", name, provinces[name]))
+	}
+
+	return strings.Join(counts, " ")
+}
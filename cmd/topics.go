@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/mdelapenya/cansino/topics"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var taxonomyParam string
+
+func init() {
+	addReportFlags(topicsCmd)
+	topicsCmd.Flags().StringVarP(&intervalParam, "interval", "i", "month", "Sets the period of the shares: day, week, month or year")
+	topicsCmd.Flags().StringVarP(&taxonomyParam, "taxonomy", "t", "", "Sets the JSON file with the terms of each topic")
+
+	rootCmd.AddCommand(topicsCmd)
+}
+
+var topicsCmd = &cobra.Command{
+	Use:   "topics",
+	Short: "Reports the topic shares",
+	Long:  "Reports the share of stored events tagged with each policy area, per owner and period",
+	Run: func(cmd *cobra.Command, args []string) {
+		period, err := periodOf(intervalParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Wrong interval")
+		}
+
+		tagger := newTagger()
+		shares := tagger.Shares(searchEvents(), period)
+
+		rows := [][]string{}
+		for _, share := range shares {
+			rows = append(rows, []string{
+				share.Owner, share.Period, share.Topic,
+				strconv.Itoa(share.Events), strconv.Itoa(share.Total),
+				fmt.Sprintf("%.2f", share.Share), fmt.Sprintf("%.1f", share.Hours),
+			})
+		}
+
+		header := []string{"OWNER", "PERIOD", "TOPIC", "EVENTS", "TOTAL", "SHARE", "HOURS"}
+		err = writeReport(os.Stdout, formatParam, header, rows, shares)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Fatal("Cannot write the report")
+		}
+	},
+}
+
+// newTagger returns a tagger for the taxonomy file, or for the default one if not set
+func newTagger() *topics.Tagger {
+	taxonomy := topics.DefaultTaxonomy()
+	if taxonomyParam != "" {
+		var err error
+		taxonomy, err = topics.LoadTaxonomy(taxonomyParam)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"taxonomy": taxonomyParam,
+				"error":    err,
+			}).Fatal("Cannot load the taxonomy")
+		}
+	}
+
+	return topics.NewTagger(taxonomy)
+}
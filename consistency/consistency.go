@@ -0,0 +1,118 @@
+package consistency
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mdelapenya/cansino/geo"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Kinds of anomalies
+const (
+	Overlap = "overlap"
+	Travel  = "travel"
+)
+
+// Anomaly represents two events of an owner that cannot both be attended: an event and the
+// one ending last before it
+type Anomaly struct {
+	Kind   string             `json:"kind"`
+	Owner  string             `json:"owner"`
+	Region string             `json:"region"`
+	First  models.AgendaEvent `json:"first"`
+	Second models.AgendaEvent `json:"second"`
+	// Gap, in minutes, from the estimated end of the first event to the start of the second
+	// one, negative if they overlap
+	Gap int `json:"gap"`
+	// Distance between the places of the events, in kilometers, if both are known
+	Distance float64 `json:"distance"`
+	// Required minutes to travel the distance at the travel speed
+	Required int `json:"required"`
+}
+
+// Check returns the anomalies of the events of each owner: events overlapping in time with
+// the event ending last before them, given their end date or their estimated duration, and
+// events too far apart from it to travel between them in the gap at the travel speed, in
+// km/h. Events without an exact time are not checked, neither the ones indexed without
+// time precision at midnight
+func Check(events []models.AgendaEvent, gazetteer *geo.Gazetteer, duration time.Duration, speed float64) []Anomaly {
+	byOwner := map[string][]models.AgendaEvent{}
+	owners := []string{}
+	for _, event := range events {
+		if event.AllDay || (event.TimePrecision != "" && event.TimePrecision != models.MinutePrecision) {
+			continue
+		}
+		if event.TimePrecision == "" && event.Date.Hour() == 0 && event.Date.Minute() == 0 {
+			continue
+		}
+
+		key := event.Owner + "\x00" + event.Region
+		if _, ok := byOwner[key]; !ok {
+			owners = append(owners, key)
+		}
+		byOwner[key] = append(byOwner[key], event)
+	}
+	sort.Strings(owners)
+
+	anomalies := []Anomaly{}
+	for _, owner := range owners {
+		agenda := byOwner[owner]
+		sort.SliceStable(agenda, func(i, j int) bool {
+			return agenda[i].Date.Before(agenda[j].Date)
+		})
+
+		// the event ending last so far, as a long event may overlap several later ones
+		latest := agenda[0]
+		for i := 1; i < len(agenda); i++ {
+			first := latest
+			second := agenda[i]
+			if end(second, duration).After(end(latest, duration)) {
+				latest = second
+			}
+
+			anomaly := Anomaly{
+				Owner:  first.Owner,
+				Region: first.Region,
+				First:  first,
+				Second: second,
+			}
+			gap := second.Date.Sub(end(first, duration))
+			anomaly.Gap = int(gap.Minutes())
+
+			if gap < 0 {
+				anomaly.Kind = Overlap
+				anomalies = append(anomalies, anomaly)
+				continue
+			}
+
+			from, ok := gazetteer.Resolve(first.OriginalLocation, first.Region)
+			if !ok {
+				continue
+			}
+			to, ok := gazetteer.Resolve(second.OriginalLocation, second.Region)
+			if !ok || from.INECode == to.INECode {
+				continue
+			}
+
+			anomaly.Distance = geo.Distance(from, to)
+			required := time.Duration(anomaly.Distance / speed * float64(time.Hour))
+			anomaly.Required = int(required.Minutes())
+			if gap < required {
+				anomaly.Kind = Travel
+				anomalies = append(anomalies, anomaly)
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// end returns the end date of an event, or its estimated end with the duration
+func end(event models.AgendaEvent, duration time.Duration) time.Time {
+	if event.EndDate != nil {
+		return *event.EndDate
+	}
+
+	return event.Date.Add(duration)
+}
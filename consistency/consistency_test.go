@@ -0,0 +1,137 @@
+package consistency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/geo"
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestCheck(t *testing.T) {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2020, 3, 2, hour, minute, 0, 0, time.UTC)
+	}
+	event := func(id string, start time.Time, end *time.Time, location string) models.AgendaEvent {
+		return models.AgendaEvent{
+			ID:               id,
+			Date:             start,
+			EndDate:          end,
+			Owner:            "Presidente",
+			Region:           "Castilla-La Mancha",
+			OriginalLocation: location,
+			TimePrecision:    models.MinutePrecision,
+		}
+	}
+	until := func(hour int) *time.Time {
+		end := at(hour, 0)
+		return &end
+	}
+
+	tests := []struct {
+		name      string
+		events    []models.AgendaEvent
+		anomalies []Anomaly
+	}{
+		{
+			name: "consecutive events",
+			events: []models.AgendaEvent{
+				event("a", at(10, 0), nil, "Toledo"),
+				event("b", at(11, 0), nil, "Toledo"),
+			},
+			anomalies: []Anomaly{},
+		},
+		{
+			name: "estimated overlap",
+			events: []models.AgendaEvent{
+				event("a", at(10, 0), nil, "Toledo"),
+				event("b", at(10, 30), nil, "Toledo"),
+			},
+			anomalies: []Anomaly{{Kind: Overlap, First: models.AgendaEvent{ID: "a"}, Second: models.AgendaEvent{ID: "b"}, Gap: -30}},
+		},
+		{
+			name: "long event overlapping non-adjacent events",
+			events: []models.AgendaEvent{
+				event("long", at(9, 0), until(14), "Toledo"),
+				event("b", at(10, 0), until(11), "Toledo"),
+				event("c", at(12, 0), until(13), "Toledo"),
+				event("d", at(15, 0), nil, "Toledo"),
+			},
+			anomalies: []Anomaly{
+				{Kind: Overlap, First: models.AgendaEvent{ID: "long"}, Second: models.AgendaEvent{ID: "b"}, Gap: -240},
+				{Kind: Overlap, First: models.AgendaEvent{ID: "long"}, Second: models.AgendaEvent{ID: "c"}, Gap: -120},
+			},
+		},
+		{
+			name: "travel too long",
+			events: []models.AgendaEvent{
+				event("a", at(10, 0), until(11), "Toledo"),
+				event("b", at(12, 0), nil, "Mérida"),
+			},
+			anomalies: []Anomaly{{Kind: Travel, First: models.AgendaEvent{ID: "a"}, Second: models.AgendaEvent{ID: "b"}, Gap: 60}},
+		},
+		{
+			name: "travel after a long event",
+			events: []models.AgendaEvent{
+				event("long", at(9, 0), until(14), "Mérida"),
+				event("b", at(10, 0), until(11), "Mérida"),
+				event("c", at(15, 0), nil, "Toledo"),
+			},
+			anomalies: []Anomaly{
+				{Kind: Overlap, First: models.AgendaEvent{ID: "long"}, Second: models.AgendaEvent{ID: "b"}, Gap: -240},
+				{Kind: Travel, First: models.AgendaEvent{ID: "long"}, Second: models.AgendaEvent{ID: "c"}, Gap: 60},
+			},
+		},
+		{
+			name: "travel in time",
+			events: []models.AgendaEvent{
+				event("a", at(9, 0), until(10), "Toledo"),
+				event("b", at(14, 0), nil, "Mérida"),
+			},
+			anomalies: []Anomaly{},
+		},
+		{
+			name: "unknown places",
+			events: []models.AgendaEvent{
+				event("a", at(10, 0), until(11), "Palacio de Congresos"),
+				event("b", at(11, 30), nil, "Mérida"),
+			},
+			anomalies: []Anomaly{},
+		},
+	}
+
+	gazetteer := geo.NewGazetteer()
+	for _, test := range tests {
+		anomalies := Check(test.events, gazetteer, time.Hour, 80)
+		if len(anomalies) != len(test.anomalies) {
+			t.Errorf("%s: unexpected anomalies %+v", test.name, anomalies)
+			continue
+		}
+
+		for i, expected := range test.anomalies {
+			anomaly := anomalies[i]
+			if anomaly.Kind != expected.Kind || anomaly.First.ID != expected.First.ID || anomaly.Second.ID != expected.Second.ID || anomaly.Gap != expected.Gap {
+				t.Errorf("%s: expected %s %s-%s (%d), got %s %s-%s (%d)", test.name,
+					expected.Kind, expected.First.ID, expected.Second.ID, expected.Gap,
+					anomaly.Kind, anomaly.First.ID, anomaly.Second.ID, anomaly.Gap)
+			}
+			if anomaly.Kind == Travel && (anomaly.Distance < 200 || anomaly.Required <= anomaly.Gap) {
+				t.Errorf("%s: unexpected travel %+v", test.name, anomaly)
+			}
+		}
+	}
+}
+
+func TestCheckSkipsUntimedEvents(t *testing.T) {
+	date := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+	events := []models.AgendaEvent{
+		{ID: "all-day", Date: date, AllDay: true, Owner: "Presidente", TimePrecision: models.DayPrecision},
+		{ID: "morning", Date: date.Add(9 * time.Hour), Owner: "Presidente", TimePrecision: models.PartOfDayPrecision},
+		{ID: "midnight", Date: date, Owner: "Presidente"},
+		{ID: "meeting", Date: date.Add(10 * time.Hour), Owner: "Presidente", TimePrecision: models.MinutePrecision},
+	}
+
+	if anomalies := Check(events, geo.NewGazetteer(), time.Hour, 80); len(anomalies) != 0 {
+		t.Errorf("unexpected anomalies %+v", anomalies)
+	}
+}
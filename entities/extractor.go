@@ -0,0 +1,343 @@
+package entities
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// name of a person: from two to five capitalised words, allowing particles in surnames
+const namePattern = `\p{Lu}[\p{L}'’-]+(?:\s+(?:(?:de|del|de la|de los|y)\s+)?\p{Lu}[\p{L}'’-]+){1,4}`
+
+// affiliation of a position: "de Sanidad", "del Gobierno de España"...
+const affiliationPattern = `\s+(?:de|del)\s+[^,;:()]{1,80}?`
+
+var wordRegexp = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'’.&-]*`)
+
+// connectors allowed inside the name of an organization
+var connectors = map[string]bool{
+	"de": true, "del": true, "la": true, "las": true, "los": true, "el": true,
+	"y": true, "e": true, "para": true,
+}
+
+// Extractor finds people, with their positions, and organizations in a text
+type Extractor struct {
+	known          []knownOrganization
+	triggers       []knownOrganization
+	position       *regexp.Regexp
+	positionBefore *regexp.Regexp
+	positionAfter  *regexp.Regexp
+}
+
+type knownOrganization struct {
+	name    string
+	orgType string
+	words   []string
+	acronym bool
+}
+
+type word struct {
+	text string
+	norm string
+	// separated is true if there is punctuation between the previous word and this one
+	separated bool
+}
+
+// NewExtractor returns an extractor for the gazetteer
+func NewExtractor(gazetteer Gazetteer) *Extractor {
+	positions := make([]string, len(gazetteer.Positions))
+	copy(positions, gazetteer.Positions)
+	// longest positions first, so that "consejero delegado" wins over "consejero"
+	sort.Slice(positions, func(i, j int) bool {
+		return len(positions[i]) > len(positions[j])
+	})
+	for i, position := range positions {
+		positions[i] = regexp.QuoteMeta(position)
+	}
+	alternation := strings.Join(positions, "|")
+
+	extractor := &Extractor{
+		position: regexp.MustCompile(`(?:^|[^\p{L}])((?i:` + alternation + `))(?:[^\p{L}]|$)`),
+		positionBefore: regexp.MustCompile(
+			`(?:^|[^\p{L}])((?i:` + alternation + `)(?:` + affiliationPattern + `)?)\s*,\s+(` + namePattern + `)`),
+		positionAfter: regexp.MustCompile(
+			`(` + namePattern + `)\s*,\s+(?:(?i:el|la)\s+)?((?i:` + alternation + `)` + affiliationPattern + `)\s*[,;:.()]`),
+	}
+
+	for name, orgType := range gazetteer.Organizations {
+		extractor.known = append(extractor.known, newKnownOrganization(name, orgType))
+	}
+	for trigger, orgType := range gazetteer.Triggers {
+		extractor.triggers = append(extractor.triggers, newKnownOrganization(trigger, orgType))
+	}
+	sort.Slice(extractor.known, func(i, j int) bool {
+		return extractor.known[i].name < extractor.known[j].name
+	})
+	sort.Slice(extractor.triggers, func(i, j int) bool {
+		return extractor.triggers[i].name < extractor.triggers[j].name
+	})
+
+	return extractor
+}
+
+func newKnownOrganization(name string, orgType string) knownOrganization {
+	return knownOrganization{
+		name:    name,
+		orgType: orgType,
+		words:   strings.Fields(analysis.Normalize(name)),
+		acronym: len(name) > 1 && strings.ToUpper(name) == name,
+	}
+}
+
+// Extract returns the people and the organizations mentioned in a text
+func (ex *Extractor) Extract(text string) ([]models.Attendee, []models.Organization) {
+	return ex.extractPeople(text), ex.extractOrganizations(text)
+}
+
+// ExtractEvent adds the people and organizations mentioned in the description of an event
+// to its attendance and organizations, skipping the owner of the event and duplicates.
+// The jobs of the attendees are used to find organizations too
+func (ex *Extractor) ExtractEvent(event *models.AgendaEvent) {
+	attendees, organizations := ex.Extract(event.OriginalDescription)
+
+	for _, attendee := range attendees {
+		if isOwner(attendee.Job, event.Region) {
+			continue
+		}
+
+		duplicated := false
+		for _, existing := range event.Attendance {
+			if analysis.Normalize(existing.FullName) == analysis.Normalize(attendee.FullName) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			event.Attendance = append(event.Attendance, attendee)
+		}
+	}
+
+	for _, attendee := range event.Attendance {
+		organizations = append(organizations, ex.extractOrganizations(attendee.Job)...)
+	}
+
+	seen := map[string]bool{}
+	for _, organization := range event.Organizations {
+		seen[analysis.Normalize(organization.Name)] = true
+	}
+	for _, organization := range organizations {
+		key := analysis.Normalize(organization.Name)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		event.Organizations = append(event.Organizations, organization)
+	}
+}
+
+func (ex *Extractor) extractPeople(text string) []models.Attendee {
+	attendees := []models.Attendee{}
+	seen := map[string]bool{}
+
+	add := func(job string, fullName string) {
+		key := analysis.Normalize(fullName)
+		if seen[key] {
+			return
+		}
+
+		seen[key] = true
+		attendees = append(attendees, models.Attendee{
+			Job:      strings.TrimSpace(job),
+			FullName: strings.TrimSpace(fullName),
+		})
+	}
+
+	for _, matches := range ex.positionBefore.FindAllStringSubmatch(text, -1) {
+		job := matches[1]
+		// "el presidente de la Junta se reúne con el presidente de CEOE, Antonio Garamendi"
+		// belongs to the last position before the name
+		if positions := ex.position.FindAllStringSubmatchIndex(job, -1); len(positions) > 1 {
+			job = job[positions[len(positions)-1][2]:]
+		}
+
+		add(job, matches[2])
+	}
+	for _, matches := range ex.positionAfter.FindAllStringSubmatch(text, -1) {
+		add(matches[2], matches[1])
+	}
+
+	return attendees
+}
+
+func (ex *Extractor) extractOrganizations(text string) []models.Organization {
+	words := splitWords(text)
+
+	organizations := []models.Organization{}
+	seen := map[string]bool{}
+	add := func(name string, orgType string) {
+		key := analysis.Normalize(name)
+		if key == "" || seen[key] {
+			return
+		}
+
+		seen[key] = true
+		organizations = append(organizations, models.Organization{Name: name, Type: orgType})
+	}
+
+	// words inside the name of an organization, so that "Caja Rural" is not found again
+	// inside "Fundación Caja Rural de Toledo"
+	covered := make([]bool, len(words))
+	cover := func(start int, end int) {
+		for j := start; j < end; j++ {
+			covered[j] = true
+		}
+	}
+
+	for i := range words {
+		for _, trigger := range ex.triggers {
+			if !matchesAt(words, i, trigger) {
+				continue
+			}
+
+			end := extendName(words, i+len(trigger.words))
+			if end == i+len(trigger.words) {
+				// a trigger alone, as "Universidad", is not the name of an organization
+				continue
+			}
+
+			add(joinWords(words[i:end]), trigger.orgType)
+			cover(i, end)
+		}
+
+		if isCompanySuffix(words[i].text) {
+			// the suffix may follow a comma, as in "Abengoa, S.A."
+			start := i
+			for start > 0 && (start == i || !words[start].separated) && isCapitalized(words[start-1].text) {
+				start--
+			}
+			if start < i {
+				add(joinWords(words[start:i]), Company)
+				cover(start, i)
+			}
+		}
+	}
+
+	for i := range words {
+		if covered[i] {
+			continue
+		}
+
+		for _, known := range ex.known {
+			if matchesAt(words, i, known) {
+				add(known.name, known.orgType)
+			}
+		}
+	}
+
+	return organizations
+}
+
+// matchesAt checks if the known organization is mentioned starting at the i-th word.
+// Acronyms must be written in capitals, and names must start with a capital letter
+func matchesAt(words []word, i int, known knownOrganization) bool {
+	if len(known.words) == 0 || i+len(known.words) > len(words) {
+		return false
+	}
+
+	for j, knownWord := range known.words {
+		if words[i+j].norm != knownWord || (j > 0 && words[i+j].separated) {
+			return false
+		}
+	}
+
+	if known.acronym {
+		return strings.ToUpper(words[i].text) == words[i].text
+	}
+
+	return isCapitalized(words[i].text)
+}
+
+// extendName returns the index after the last capitalised word continuing a name from
+// the start index, allowing connectors between capitalised words
+func extendName(words []word, start int) int {
+	end := start
+	for j := start; j < len(words) && !words[j].separated; j++ {
+		if isCapitalized(words[j].text) {
+			end = j + 1
+		} else if !connectors[words[j].norm] {
+			break
+		}
+	}
+
+	return end
+}
+
+func splitWords(text string) []word {
+	words := []word{}
+
+	previousEnd := 0
+	for _, loc := range wordRegexp.FindAllStringIndex(text, -1) {
+		raw := text[loc[0]:loc[1]]
+		if strings.HasSuffix(raw, ".") && strings.Count(raw, ".") == 1 {
+			// the end of a sentence, not an abbreviation
+			raw = strings.TrimSuffix(raw, ".")
+		}
+
+		gap := text[previousEnd:loc[0]]
+		words = append(words, word{
+			text:      raw,
+			norm:      analysis.Normalize(raw),
+			separated: len(words) > 0 && strings.TrimSpace(gap) != "",
+		})
+
+		previousEnd = loc[1]
+		if len(raw) < loc[1]-loc[0] {
+			// keep the removed dot as punctuation before the next word
+			previousEnd = loc[0] + len(raw)
+		}
+	}
+
+	return words
+}
+
+func joinWords(words []word) string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+	}
+
+	return strings.Join(texts, " ")
+}
+
+func isCapitalized(text string) bool {
+	for _, r := range text {
+		return unicode.IsUpper(r)
+	}
+
+	return false
+}
+
+func isCompanySuffix(text string) bool {
+	switch text {
+	case "S.A.", "S.L.", "S.L.U.", "S.A.U.", "SA", "SL", "SLU", "SAU":
+		return true
+	}
+
+	return false
+}
+
+// isOwner checks if a job is the presidency of the region, as the owner of the agenda
+// is usually mentioned in the descriptions of the events
+func isOwner(job string, region string) bool {
+	normalizedJob := strings.ReplaceAll(" "+analysis.Normalize(job)+" ", " y ", " ")
+	normalizedRegion := strings.ReplaceAll(" "+analysis.Normalize(region)+" ", " y ", " ")
+
+	return strings.HasPrefix(strings.TrimSpace(normalizedJob), "president") &&
+		strings.TrimSpace(normalizedRegion) != "" &&
+		strings.Contains(normalizedJob, normalizedRegion)
+}
@@ -0,0 +1,89 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestExtractEvent(t *testing.T) {
+	extractor := NewExtractor(DefaultGazetteer())
+
+	event := &models.AgendaEvent{
+		Region: "Castilla-La Mancha",
+		OriginalDescription: "El presidente de la Junta de Comunidades de Castilla-La Mancha, Emiliano García-Page, " +
+			"se reúne con el presidente de CEOE, Antonio Garamendi, y con representantes de CCOO y de Iberdrola, S.A.",
+		Attendance: []models.Attendee{{FullName: "Antonio Garamendi"}},
+	}
+	extractor.ExtractEvent(event)
+
+	if len(event.Attendance) != 1 || event.Attendance[0].FullName != "Antonio Garamendi" {
+		t.Errorf("unexpected attendance %+v", event.Attendance)
+	}
+
+	organizations := map[string]string{}
+	for _, organization := range event.Organizations {
+		organizations[organization.Name] = organization.Type
+	}
+	for name, orgType := range map[string]string{"CEOE": Association, "CCOO": Union, "Iberdrola": Company} {
+		if organizations[name] != orgType {
+			t.Errorf("expected %s (%s), got %+v", name, orgType, event.Organizations)
+		}
+	}
+}
+
+func TestExtractPeople(t *testing.T) {
+	extractor := NewExtractor(DefaultGazetteer())
+
+	tests := []struct {
+		text     string
+		job      string
+		fullName string
+	}{
+		{"Reunión con el presidente de CEOE, Antonio Garamendi", "presidente de CEOE", "Antonio Garamendi"},
+		{"Reunión con Antonio Garamendi, presidente de CEOE, en Toledo", "presidente de CEOE", "Antonio Garamendi"},
+		{"Visita con la consejera de Sanidad, María José Sánchez de la Fuente", "consejera de Sanidad", "María José Sánchez de la Fuente"},
+		{"El presidente de la Junta se reúne con el secretario general de UGT, Pepe Álvarez", "secretario general de UGT", "Pepe Álvarez"},
+	}
+
+	for _, test := range tests {
+		attendees, _ := extractor.Extract(test.text)
+		if len(attendees) != 1 || attendees[0].Job != test.job || attendees[0].FullName != test.fullName {
+			t.Errorf("%q: unexpected attendees %+v", test.text, attendees)
+		}
+	}
+}
+
+func TestExtractOrganizations(t *testing.T) {
+	extractor := NewExtractor(DefaultGazetteer())
+
+	tests := []struct {
+		text          string
+		organizations []string
+	}{
+		// acronyms must be written in capitals
+		{"Reunión con CEOE y CEPYME", []string{"CEOE", "CEPYME"}},
+		{"Reunión con la ceoe", []string{}},
+		{"Patronato de la Fundación Caja Rural de Toledo", []string{"Fundación Caja Rural de Toledo"}},
+		{"Visita a la Universidad", []string{}},
+		{"Firma con Abengoa, S.A.", []string{"Abengoa"}},
+	}
+
+	for _, test := range tests {
+		_, organizations := extractor.Extract(test.text)
+		names := []string{}
+		for _, organization := range organizations {
+			names = append(names, organization.Name)
+		}
+
+		if len(names) != len(test.organizations) {
+			t.Errorf("%q: expected %v, got %v", test.text, test.organizations, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.organizations[i] {
+				t.Errorf("%q: expected %v, got %v", test.text, test.organizations, names)
+			}
+		}
+	}
+}
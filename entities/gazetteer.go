@@ -0,0 +1,93 @@
+package entities
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Organization types
+const (
+	Association = "association"
+	Company     = "company"
+	Other       = "other"
+	Public      = "public"
+	Union       = "union"
+)
+
+// Gazetteer represents the lists of known terms used to find entities in a text
+type Gazetteer struct {
+	// Positions held by people, as "presidente" or "secretaria general"
+	Positions []string `json:"positions"`
+	// Organizations are well-known organizations, keyed by name or acronym, with their type
+	Organizations map[string]string `json:"organizations"`
+	// Triggers are the words starting the name of an organization, with the type of the
+	// organization, as "Asociación" or "Sindicato"
+	Triggers map[string]string `json:"triggers"`
+}
+
+// DefaultGazetteer returns the built-in gazetteer of Spanish positions and organizations
+func DefaultGazetteer() Gazetteer {
+	return Gazetteer{
+		Positions: []string{
+			"presidente", "presidenta", "vicepresidente", "vicepresidenta",
+			"consejero delegado", "consejera delegada", "consejero", "consejera",
+			"viceconsejero", "viceconsejera", "ministro", "ministra",
+			"secretario general", "secretaria general", "secretario de estado", "secretaria de estado",
+			"director general", "directora general", "director", "directora",
+			"delegado", "delegada", "subdelegado", "subdelegada",
+			"alcalde", "alcaldesa", "teniente de alcalde", "concejal", "concejala",
+			"diputado", "diputada", "senador", "senadora", "portavoz",
+			"rector", "rectora", "decano", "decana", "embajador", "embajadora",
+			"gerente", "coordinador", "coordinadora", "responsable", "fundador", "fundadora",
+			"obispo", "arzobispo",
+		},
+		Organizations: map[string]string{
+			"CEOE": Association, "CEPYME": Association,
+			"ATA": Association, "UPTA": Association, "FEMP": Public,
+			"CCOO": Union, "Comisiones Obreras": Union, "UGT": Union, "CSIF": Union, "USO": Union,
+			"ASAJA": Association, "COAG": Association, "UPA": Association,
+			"Cruz Roja": Association, "Cáritas": Association, "ONCE": Association,
+			"Globalcaja": Company, "Eurocaja Rural": Company, "Caja Rural": Company,
+			"Unicaja": Company, "Ibercaja": Company, "CaixaBank": Company, "BBVA": Company,
+			"Banco Santander": Company, "Iberdrola": Company, "Endesa": Company,
+			"Naturgy": Company, "Repsol": Company, "Telefónica": Company, "Mercadona": Company,
+			"Renfe": Public, "Adif": Public, "Airbus": Company, "Amazon": Company,
+			"Google": Company, "Microsoft": Company,
+		},
+		Triggers: map[string]string{
+			"Asociación": Association, "Federación": Association, "Confederación": Association,
+			"Fundación": Association, "Plataforma": Association, "Colegio Oficial": Association,
+			"Cámara de Comercio": Association, "Cámara Oficial": Association,
+			"Real Academia": Association, "Club": Association, "Sindicato": Union,
+			"Grupo": Company, "Cooperativa": Company, "Sociedad Cooperativa": Company,
+			"Universidad": Public, "Ayuntamiento": Public, "Diputación": Public,
+			"Consorcio": Public, "Mancomunidad": Public, "Ministerio": Public,
+		},
+	}
+}
+
+// LoadGazetteer reads a gazetteer from a JSON file, adding its terms to the built-in ones
+func LoadGazetteer(path string) (Gazetteer, error) {
+	gazetteer := DefaultGazetteer()
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return gazetteer, err
+	}
+
+	extra := Gazetteer{}
+	err = json.Unmarshal(bytes, &extra)
+	if err != nil {
+		return gazetteer, err
+	}
+
+	gazetteer.Positions = append(gazetteer.Positions, extra.Positions...)
+	for name, orgType := range extra.Organizations {
+		gazetteer.Organizations[name] = orgType
+	}
+	for trigger, orgType := range extra.Triggers {
+		gazetteer.Triggers[trigger] = orgType
+	}
+
+	return gazetteer, nil
+}
@@ -0,0 +1,489 @@
+package entities
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Kinds of entities
+const (
+	OrganizationEntity = "organization"
+	PersonEntity       = "person"
+)
+
+// words not identifying a person, as honorifics
+var honorifics = map[string]bool{
+	"d": true, "don": true, "dna": true, "dona": true, "sr": true, "sra": true,
+	"senor": true, "senora": true, "excmo": true, "excma": true, "ilmo": true, "ilma": true,
+	"dr": true, "dra": true, "el": true, "la": true,
+}
+
+// Entity represents a canonical person or organization, with all the names it is
+// mentioned with
+type Entity struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Mentions int      `json:"mentions"`
+}
+
+// Overrides represents the manual corrections to the entity resolution
+type Overrides struct {
+	// Merges are entities whose aliases always resolve to them
+	Merges []Entity `json:"merge"`
+	// Splits are groups of names that must never resolve to the same entity
+	Splits [][]string `json:"split"`
+}
+
+// Mention represents a name found in an event
+type Mention struct {
+	Kind string
+	Name string
+}
+
+// Meetings represents the events an owner shared with an entity
+type Meetings struct {
+	EntityID string    `json:"entityId"`
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Owner    string    `json:"owner"`
+	Events   int       `json:"events"`
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+}
+
+// LoadRegistry reads the entities from a JSON file
+func LoadRegistry(path string) ([]Entity, error) {
+	registry := []Entity{}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return registry, err
+	}
+
+	err = json.Unmarshal(bytes, &registry)
+	return registry, err
+}
+
+// SaveRegistry writes the entities to a JSON file
+func SaveRegistry(path string, registry []Entity) error {
+	bytes, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, bytes, 0644)
+}
+
+// LoadOverrides reads the manual merges and splits from a JSON file
+func LoadOverrides(path string) (Overrides, error) {
+	overrides := Overrides{}
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return overrides, err
+	}
+
+	err = json.Unmarshal(bytes, &overrides)
+	return overrides, err
+}
+
+// Resolver resolves the names of people and organizations into canonical entities
+type Resolver struct {
+	entities  []Entity
+	aliases   map[string]int
+	splits    map[string]map[string]bool
+	threshold float64
+	// manual are the entities coming from the merge overrides
+	manual map[int]bool
+}
+
+// NewResolver returns a resolver for the entities of a registry and the overrides. Names
+// not in the registry resolve to the most similar entity, if their similarity is at least
+// the threshold
+func NewResolver(registry []Entity, overrides Overrides, threshold float64) *Resolver {
+	r := newResolver(overrides, threshold)
+
+	for _, entity := range registry {
+		i, ok := r.aliases[entity.Kind+"|"+canonicalKey(entity.Kind, entity.Name)]
+		if ok && r.manual[i] {
+			// the manual merge wins over the registry, keeping its aliases
+			for _, alias := range entity.Aliases {
+				r.addAlias(i, alias)
+			}
+			continue
+		}
+
+		r.add(entity)
+	}
+
+	return r
+}
+
+func newResolver(overrides Overrides, threshold float64) *Resolver {
+	r := &Resolver{
+		entities:  []Entity{},
+		aliases:   map[string]int{},
+		manual:    map[int]bool{},
+		splits:    map[string]map[string]bool{},
+		threshold: threshold,
+	}
+
+	for _, group := range overrides.Splits {
+		for _, kind := range []string{OrganizationEntity, PersonEntity} {
+			for _, name := range group {
+				key := kind + "|" + canonicalKey(kind, name)
+				for _, other := range group {
+					if name == other {
+						continue
+					}
+
+					if r.splits[key] == nil {
+						r.splits[key] = map[string]bool{}
+					}
+					r.splits[key][kind+"|"+canonicalKey(kind, other)] = true
+				}
+			}
+		}
+	}
+
+	for _, merge := range overrides.Merges {
+		if merge.ID == "" {
+			merge.ID = stableID(merge.Kind, canonicalKey(merge.Kind, merge.Name))
+		}
+		merge.Mentions = 0
+
+		r.add(merge)
+		r.manual[len(r.entities)-1] = true
+	}
+
+	return r
+}
+
+// Resolve returns the ID of the entity for a name
+func (r *Resolver) Resolve(kind string, name string) string {
+	if i := r.find(kind, name); i >= 0 {
+		return r.entities[i].ID
+	}
+
+	return stableID(kind, canonicalKey(kind, name))
+}
+
+// ResolveEvent sets the IDs of the attendees and organizations of an event
+func (r *Resolver) ResolveEvent(event *models.AgendaEvent) {
+	for i, attendee := range event.Attendance {
+		if attendee.FullName == "" {
+			continue
+		}
+
+		event.Attendance[i].ID = r.Resolve(PersonEntity, attendee.FullName)
+	}
+
+	for i, organization := range event.Organizations {
+		event.Organizations[i].ID = r.Resolve(OrganizationEntity, organization.Name)
+	}
+}
+
+// Meetings returns, per entity and owner, the events in which they met, sorted by number
+// of events
+func (r *Resolver) Meetings(events []models.AgendaEvent) []Meetings {
+	type key struct {
+		id    string
+		owner string
+	}
+
+	meetings := map[key]*Meetings{}
+	add := func(event models.AgendaEvent, kind string, name string, seen map[string]bool) {
+		id := r.Resolve(kind, name)
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+
+		k := key{id: id, owner: event.Owner}
+		m, ok := meetings[k]
+		if !ok {
+			m = &Meetings{EntityID: id, Kind: kind, Name: name, Owner: event.Owner, First: event.Date}
+			if i := r.find(kind, name); i >= 0 {
+				m.Name = r.entities[i].Name
+			}
+			meetings[k] = m
+		}
+
+		m.Events++
+		if event.Date.Before(m.First) {
+			m.First = event.Date
+		}
+		if event.Date.After(m.Last) {
+			m.Last = event.Date
+		}
+	}
+
+	for _, event := range events {
+		seen := map[string]bool{}
+		for _, attendee := range event.Attendance {
+			if attendee.FullName != "" {
+				add(event, PersonEntity, attendee.FullName, seen)
+			}
+		}
+		for _, organization := range event.Organizations {
+			add(event, OrganizationEntity, organization.Name, seen)
+		}
+	}
+
+	result := []Meetings{}
+	for _, m := range meetings {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Events != result[j].Events {
+			return result[i].Events > result[j].Events
+		}
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].Owner < result[j].Owner
+	})
+
+	return result
+}
+
+// Entity returns the entity with the ID, or the one including the name as alias
+func (r *Resolver) Entity(kind string, nameOrID string) (Entity, bool) {
+	if entity, ok := r.EntityByID(nameOrID); ok {
+		return entity, true
+	}
+
+	if i := r.find(kind, nameOrID); i >= 0 {
+		return r.entities[i], true
+	}
+
+	return Entity{}, false
+}
+
+// EntityByID returns the entity with the ID
+func (r *Resolver) EntityByID(id string) (Entity, bool) {
+	for _, entity := range r.entities {
+		if entity.ID == id {
+			return entity, true
+		}
+	}
+
+	return Entity{}, false
+}
+
+// Mentions returns the names of the people and organizations of the events
+func Mentions(events []models.AgendaEvent) []Mention {
+	mentions := []Mention{}
+	for _, event := range events {
+		for _, attendee := range event.Attendance {
+			if attendee.FullName != "" {
+				mentions = append(mentions, Mention{Kind: PersonEntity, Name: attendee.FullName})
+			}
+		}
+		for _, organization := range event.Organizations {
+			mentions = append(mentions, Mention{Kind: OrganizationEntity, Name: organization.Name})
+		}
+	}
+
+	return mentions
+}
+
+// Cluster groups the mentions into entities, named after their most frequent variant.
+// Entities keep the ID they had in the previous registry, if any of their aliases was
+// there, so that IDs are stable across runs
+func Cluster(mentions []Mention, previous []Entity, overrides Overrides, threshold float64) []Entity {
+	type group struct {
+		kind     string
+		key      string
+		variants map[string]int
+		total    int
+	}
+
+	groups := map[string]*group{}
+	for _, mention := range mentions {
+		key := canonicalKey(mention.Kind, mention.Name)
+		if key == "" {
+			continue
+		}
+
+		g, ok := groups[mention.Kind+"|"+key]
+		if !ok {
+			g = &group{kind: mention.Kind, key: key, variants: map[string]int{}}
+			groups[mention.Kind+"|"+key] = g
+		}
+		g.variants[mention.Name]++
+		g.total++
+	}
+
+	sorted := []*group{}
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].total != sorted[j].total {
+			return sorted[i].total > sorted[j].total
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	previousResolver := NewResolver(previous, Overrides{}, 1)
+	r := newResolver(overrides, threshold)
+	for _, g := range sorted {
+		variants := []string{}
+		for variant := range g.variants {
+			variants = append(variants, variant)
+		}
+		sort.Slice(variants, func(i, j int) bool {
+			if g.variants[variants[i]] != g.variants[variants[j]] {
+				return g.variants[variants[i]] > g.variants[variants[j]]
+			}
+			return variants[i] < variants[j]
+		})
+
+		i := r.find(g.kind, variants[0])
+		if i < 0 {
+			id := stableID(g.kind, g.key)
+			if j := previousResolver.find(g.kind, variants[0]); j >= 0 {
+				id = previousResolver.entities[j].ID
+			}
+
+			r.add(Entity{ID: id, Kind: g.kind, Name: variants[0], Aliases: []string{}})
+			i = len(r.entities) - 1
+		}
+
+		for _, variant := range variants {
+			r.addAlias(i, variant)
+		}
+		r.entities[i].Mentions += g.total
+	}
+
+	result := []Entity{}
+	for _, entity := range r.entities {
+		if entity.Mentions > 0 {
+			result = append(result, entity)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Mentions > result[j].Mentions
+	})
+
+	return result
+}
+
+func (r *Resolver) add(entity Entity) {
+	aliases := entity.Aliases
+	entity.Aliases = []string{}
+
+	r.entities = append(r.entities, entity)
+	i := len(r.entities) - 1
+
+	r.addAlias(i, entity.Name)
+	for _, alias := range aliases {
+		r.addAlias(i, alias)
+	}
+}
+
+func (r *Resolver) addAlias(i int, alias string) {
+	entity := &r.entities[i]
+
+	key := entity.Kind + "|" + canonicalKey(entity.Kind, alias)
+	if _, exists := r.aliases[key]; !exists {
+		r.aliases[key] = i
+	}
+
+	for _, existing := range entity.Aliases {
+		if existing == alias {
+			return
+		}
+	}
+	entity.Aliases = append(entity.Aliases, alias)
+}
+
+// find returns the index of the entity for a name: the entity including the name as alias,
+// or the one with the most similar distinctive words, unless a split forbids it. It returns
+// -1 if there is none
+func (r *Resolver) find(kind string, name string) int {
+	key := canonicalKey(kind, name)
+	if key == "" {
+		return -1
+	}
+
+	if i, ok := r.aliases[kind+"|"+key]; ok {
+		return i
+	}
+
+	best := -1
+	bestScore := r.threshold
+	for i, entity := range r.entities {
+		if entity.Kind != kind || r.isSplit(kind+"|"+key, entity) {
+			continue
+		}
+
+		for _, alias := range entity.Aliases {
+			score := analysis.TokenSimilarity(key, canonicalKey(kind, alias))
+			if score >= bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+	}
+
+	return best
+}
+
+func (r *Resolver) isSplit(key string, entity Entity) bool {
+	splits := r.splits[key]
+	if len(splits) == 0 {
+		return false
+	}
+
+	for _, alias := range entity.Aliases {
+		if splits[entity.Kind+"|"+canonicalKey(entity.Kind, alias)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canonicalKey returns the words identifying a name, sorted, so that "García Page, Emiliano"
+// and "D. Emiliano García-Page" share the same key
+func canonicalKey(kind string, name string) string {
+	words := []string{}
+	for _, word := range strings.Fields(analysis.Normalize(name)) {
+		if kind == PersonEntity && honorifics[word] {
+			continue
+		}
+		if kind == OrganizationEntity && len(word) == 1 {
+			// legal forms, as "S. A." or "S. L."
+			continue
+		}
+
+		words = append(words, word)
+	}
+
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+// stableID returns an ID derived from the canonical key of a name
+func stableID(kind string, key string) string {
+	prefix := "person-"
+	if kind == OrganizationEntity {
+		prefix = "org-"
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return prefix + hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,62 @@
+package entities
+
+import "testing"
+
+func TestResolverDistinctEntities(t *testing.T) {
+	registry := []Entity{
+		{ID: "org-telefonica", Kind: OrganizationEntity, Name: "Fundación Telefónica"},
+		{ID: "org-abengoa", Kind: OrganizationEntity, Name: "Abengoa"},
+		{ID: "person-page", Kind: PersonEntity, Name: "Emiliano García-Page"},
+	}
+	r := NewResolver(registry, Overrides{}, 0.9)
+
+	tests := []struct {
+		kind string
+		name string
+		id   string
+	}{
+		{OrganizationEntity, "Telefónica, Fundación", "org-telefonica"},
+		{OrganizationEntity, "Abengoa, S.A.", "org-abengoa"},
+		{PersonEntity, "D. Emiliano García Page", "person-page"},
+		{PersonEntity, "Emiliano Garcia Paje", "person-page"},
+		{OrganizationEntity, "de Fundación Iberdrola", ""},
+		{OrganizationEntity, "Abengoa Solar", ""},
+		{PersonEntity, "Emiliano García", ""},
+	}
+
+	for _, test := range tests {
+		id := r.Resolve(test.kind, test.name)
+		if test.id == "" {
+			if id == "org-telefonica" || id == "org-abengoa" || id == "person-page" {
+				t.Errorf("%q resolved to the distinct entity %s", test.name, id)
+			}
+			continue
+		}
+
+		if id != test.id {
+			t.Errorf("%q resolved to %s, want %s", test.name, id, test.id)
+		}
+	}
+}
+
+func TestResolverSplits(t *testing.T) {
+	registry := []Entity{{ID: "org-caja", Kind: OrganizationEntity, Name: "Caja Rural"}}
+	overrides := Overrides{Splits: [][]string{{"Caja Rural", "Caja Rurales"}}}
+	r := NewResolver(registry, overrides, 0.9)
+
+	if id := r.Resolve(OrganizationEntity, "Caja Rurales"); id == "org-caja" {
+		t.Errorf("split name resolved to %s", id)
+	}
+}
+
+func TestEntityByID(t *testing.T) {
+	registry := []Entity{{ID: "org-abengoa", Kind: OrganizationEntity, Name: "Abengoa"}}
+	r := NewResolver(registry, Overrides{}, 0.9)
+
+	if entity, ok := r.EntityByID("org-abengoa"); !ok || entity.Name != "Abengoa" {
+		t.Errorf("unexpected entity %+v", entity)
+	}
+	if entity, ok := r.EntityByID("Abengoa"); ok {
+		t.Errorf("the name %q was looked up as an ID: %+v", "Abengoa", entity)
+	}
+}
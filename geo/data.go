@@ -0,0 +1,204 @@
+package geo
+
+// regionNames are removed from the locations before looking for municipalities, as they
+// include names of municipalities
+var regionNames = []string{"castilla y leon", "castilla leon", "castilla la mancha"}
+
+// capitals are the INE codes of the capitals of the regions with agendas
+var capitals = map[string]string{
+	"Castilla-La Mancha": "45168",
+	"Castilla-León":      "47186",
+	"Extremadura":        "06083",
+	"Madrid":             "28079",
+}
+
+// regionMunicipalities are the number of municipalities of the regions with agendas, by INE
+var regionMunicipalities = map[string]int{
+	"Castilla-La Mancha": 919,
+	"Castilla-León":      2248,
+	"Extremadura":        388,
+	"Madrid":             179,
+}
+
+// bundledProvinces are the Spanish provinces, by INE code
+var bundledProvinces = []Province{
+	{Code: "01", Name: "Araba/Álava", Region: "País Vasco"},
+	{Code: "02", Name: "Albacete", Region: "Castilla-La Mancha"},
+	{Code: "03", Name: "Alicante", Region: "Comunidad Valenciana"},
+	{Code: "04", Name: "Almería", Region: "Andalucía"},
+	{Code: "05", Name: "Ávila", Region: "Castilla-León"},
+	{Code: "06", Name: "Badajoz", Region: "Extremadura"},
+	{Code: "07", Name: "Illes Balears", Region: "Illes Balears"},
+	{Code: "08", Name: "Barcelona", Region: "Cataluña"},
+	{Code: "09", Name: "Burgos", Region: "Castilla-León"},
+	{Code: "10", Name: "Cáceres", Region: "Extremadura"},
+	{Code: "11", Name: "Cádiz", Region: "Andalucía"},
+	{Code: "12", Name: "Castellón", Region: "Comunidad Valenciana"},
+	{Code: "13", Name: "Ciudad Real", Region: "Castilla-La Mancha"},
+	{Code: "14", Name: "Córdoba", Region: "Andalucía"},
+	{Code: "15", Name: "A Coruña", Region: "Galicia"},
+	{Code: "16", Name: "Cuenca", Region: "Castilla-La Mancha"},
+	{Code: "17", Name: "Girona", Region: "Cataluña"},
+	{Code: "18", Name: "Granada", Region: "Andalucía"},
+	{Code: "19", Name: "Guadalajara", Region: "Castilla-La Mancha"},
+	{Code: "20", Name: "Gipuzkoa", Region: "País Vasco"},
+	{Code: "21", Name: "Huelva", Region: "Andalucía"},
+	{Code: "22", Name: "Huesca", Region: "Aragón"},
+	{Code: "23", Name: "Jaén", Region: "Andalucía"},
+	{Code: "24", Name: "León", Region: "Castilla-León"},
+	{Code: "25", Name: "Lleida", Region: "Cataluña"},
+	{Code: "26", Name: "La Rioja", Region: "La Rioja"},
+	{Code: "27", Name: "Lugo", Region: "Galicia"},
+	{Code: "28", Name: "Madrid", Region: "Madrid"},
+	{Code: "29", Name: "Málaga", Region: "Andalucía"},
+	{Code: "30", Name: "Murcia", Region: "Región de Murcia"},
+	{Code: "31", Name: "Navarra", Region: "Navarra"},
+	{Code: "32", Name: "Ourense", Region: "Galicia"},
+	{Code: "33", Name: "Asturias", Region: "Asturias"},
+	{Code: "34", Name: "Palencia", Region: "Castilla-León"},
+	{Code: "35", Name: "Las Palmas", Region: "Canarias"},
+	{Code: "36", Name: "Pontevedra", Region: "Galicia"},
+	{Code: "37", Name: "Salamanca", Region: "Castilla-León"},
+	{Code: "38", Name: "Santa Cruz de Tenerife", Region: "Canarias"},
+	{Code: "39", Name: "Cantabria", Region: "Cantabria"},
+	{Code: "40", Name: "Segovia", Region: "Castilla-León"},
+	{Code: "41", Name: "Sevilla", Region: "Andalucía"},
+	{Code: "42", Name: "Soria", Region: "Castilla-León"},
+	{Code: "43", Name: "Tarragona", Region: "Cataluña"},
+	{Code: "44", Name: "Teruel", Region: "Aragón"},
+	{Code: "45", Name: "Toledo", Region: "Castilla-La Mancha"},
+	{Code: "46", Name: "Valencia", Region: "Comunidad Valenciana"},
+	{Code: "47", Name: "Valladolid", Region: "Castilla-León"},
+	{Code: "48", Name: "Bizkaia", Region: "País Vasco"},
+	{Code: "49", Name: "Zamora", Region: "Castilla-León"},
+	{Code: "50", Name: "Zaragoza", Region: "Aragón"},
+	{Code: "51", Name: "Ceuta", Region: "Ceuta"},
+	{Code: "52", Name: "Melilla", Region: "Melilla"},
+}
+
+// bundledMunicipalities are the provincial capitals and the main municipalities of the
+// regions with agendas, with their approximate population. Load the MUNICIPIOS.csv file of
+// the IGN, with the whole INE list, for full coverage
+var bundledMunicipalities = []Municipality{
+	// Castilla-La Mancha
+	{INECode: "02003", Name: "Albacete", Province: "02", Lat: 38.9943, Lon: -1.8585, Population: 173000},
+	{INECode: "02009", Name: "Almansa", Province: "02", Lat: 38.8696, Lon: -1.0978, Population: 24500},
+	{INECode: "02037", Name: "Hellín", Province: "02", Lat: 38.5106, Lon: -1.7003, Population: 30000},
+	{INECode: "02081", Name: "Villarrobledo", Province: "02", Lat: 39.2697, Lon: -2.6011, Population: 25000},
+	{INECode: "13005", Name: "Alcázar de San Juan", Province: "13", Lat: 39.3902, Lon: -3.2085, Population: 30500},
+	{INECode: "13013", Name: "Almagro", Province: "13", Lat: 38.8878, Lon: -3.7122, Population: 8900},
+	{INECode: "13034", Name: "Ciudad Real", Province: "13", Lat: 38.9848, Lon: -3.9274, Population: 75000},
+	{INECode: "13071", Name: "Puertollano", Province: "13", Lat: 38.6871, Lon: -4.1073, Population: 46000},
+	{INECode: "13082", Name: "Tomelloso", Province: "13", Lat: 39.1572, Lon: -3.0241, Population: 36000},
+	{INECode: "13087", Name: "Valdepeñas", Province: "13", Lat: 38.7622, Lon: -3.3845, Population: 30000},
+	{INECode: "16078", Name: "Cuenca", Province: "16", Lat: 40.0704, Lon: -2.1374, Population: 54000},
+	{INECode: "16203", Name: "Tarancón", Province: "16", Lat: 40.0086, Lon: -3.0072, Population: 15500},
+	{INECode: "19046", Name: "Azuqueca de Henares", Province: "19", Lat: 40.5668, Lon: -3.2658, Population: 35500},
+	{INECode: "19130", Name: "Guadalajara", Province: "19", Lat: 40.6328, Lon: -3.1668, Population: 87500},
+	{INECode: "19190", Name: "Molina de Aragón", Province: "19", Lat: 40.8437, Lon: -1.8857, Population: 3300},
+	{INECode: "19257", Name: "Sigüenza", Province: "19", Lat: 41.0686, Lon: -2.6434, Population: 4300},
+	{INECode: "45053", Name: "Consuegra", Province: "45", Lat: 39.4614, Lon: -3.6079, Population: 10300},
+	{INECode: "45081", Name: "Illescas", Province: "45", Lat: 40.1221, Lon: -3.8463, Population: 30500},
+	{INECode: "45121", Name: "Ocaña", Province: "45", Lat: 39.9575, Lon: -3.4986, Population: 12000},
+	{INECode: "45165", Name: "Talavera de la Reina", Province: "45", Lat: 39.9635, Lon: -4.8308, Population: 83500},
+	{INECode: "45168", Name: "Toledo", Province: "45", Lat: 39.8628, Lon: -4.0273, Population: 85500},
+	// Castilla y León
+	{INECode: "05019", Name: "Ávila", Province: "05", Lat: 40.6565, Lon: -4.6818, Population: 57900},
+	{INECode: "09018", Name: "Aranda de Duero", Province: "09", Lat: 41.6704, Lon: -3.6892, Population: 33000},
+	{INECode: "09059", Name: "Burgos", Province: "09", Lat: 42.3439, Lon: -3.6969, Population: 174000},
+	{INECode: "09219", Name: "Miranda de Ebro", Province: "09", Lat: 42.6865, Lon: -2.9470, Population: 35500},
+	{INECode: "24008", Name: "Astorga", Province: "24", Lat: 42.4589, Lon: -6.0563, Population: 10500},
+	{INECode: "24089", Name: "León", Province: "24", Lat: 42.5987, Lon: -5.5671, Population: 122000},
+	{INECode: "24115", Name: "Ponferrada", Province: "24", Lat: 42.5460, Lon: -6.5962, Population: 63000},
+	{INECode: "24142", Name: "San Andrés del Rabanedo", Province: "24", Lat: 42.6130, Lon: -5.6132, Population: 30000},
+	{INECode: "34120", Name: "Palencia", Province: "34", Lat: 42.0095, Lon: -4.5288, Population: 77000},
+	{INECode: "37046", Name: "Béjar", Province: "37", Lat: 40.3865, Lon: -5.7632, Population: 12500},
+	{INECode: "37107", Name: "Ciudad Rodrigo", Province: "37", Lat: 40.6000, Lon: -6.5333, Population: 12000},
+	{INECode: "37274", Name: "Salamanca", Province: "37", Lat: 40.9701, Lon: -5.6635, Population: 143000},
+	{INECode: "40194", Name: "Segovia", Province: "40", Lat: 40.9429, Lon: -4.1088, Population: 51000},
+	{INECode: "42173", Name: "Soria", Province: "42", Lat: 41.7666, Lon: -2.4790, Population: 40000},
+	{INECode: "47076", Name: "Laguna de Duero", Province: "47", Lat: 41.5817, Lon: -4.7236, Population: 22500},
+	{INECode: "47085", Name: "Medina del Campo", Province: "47", Lat: 41.3120, Lon: -4.9143, Population: 20500},
+	{INECode: "47186", Name: "Valladolid", Province: "47", Lat: 41.6523, Lon: -4.7245, Population: 297000},
+	{INECode: "49021", Name: "Benavente", Province: "49", Lat: 42.0030, Lon: -5.6784, Population: 17500},
+	{INECode: "49275", Name: "Zamora", Province: "49", Lat: 41.5034, Lon: -5.7467, Population: 59500},
+	// Extremadura
+	{INECode: "06011", Name: "Almendralejo", Province: "06", Lat: 38.6837, Lon: -6.4077, Population: 33500},
+	{INECode: "06015", Name: "Badajoz", Province: "06", Lat: 38.8794, Lon: -6.9707, Population: 150000},
+	{INECode: "06044", Name: "Don Benito", Province: "06", Lat: 38.9566, Lon: -5.8614, Population: 37000},
+	{INECode: "06070", Name: "Jerez de los Caballeros", Province: "06", Lat: 38.3204, Lon: -6.7724, Population: 9000},
+	{INECode: "06074", Name: "Llerena", Province: "06", Lat: 38.2376, Lon: -6.0162, Population: 5700},
+	{INECode: "06083", Name: "Mérida", Province: "06", Lat: 38.9161, Lon: -6.3437, Population: 60000},
+	{INECode: "06153", Name: "Villanueva de la Serena", Province: "06", Lat: 38.9760, Lon: -5.7975, Population: 25500},
+	{INECode: "06158", Name: "Zafra", Province: "06", Lat: 38.4253, Lon: -6.4170, Population: 16500},
+	{INECode: "10037", Name: "Cáceres", Province: "10", Lat: 39.4753, Lon: -6.3724, Population: 96000},
+	{INECode: "10067", Name: "Coria", Province: "10", Lat: 39.9842, Lon: -6.5362, Population: 12300},
+	{INECode: "10131", Name: "Navalmoral de la Mata", Province: "10", Lat: 39.8920, Lon: -5.5410, Population: 17000},
+	{INECode: "10148", Name: "Plasencia", Province: "10", Lat: 40.0303, Lon: -6.0903, Population: 39500},
+	{INECode: "10195", Name: "Trujillo", Province: "10", Lat: 39.4604, Lon: -5.8807, Population: 8800},
+	// Madrid
+	{INECode: "28005", Name: "Alcalá de Henares", Province: "28", Lat: 40.4820, Lon: -3.3635, Population: 196000},
+	{INECode: "28006", Name: "Alcobendas", Province: "28", Lat: 40.5475, Lon: -3.6420, Population: 117000},
+	{INECode: "28007", Name: "Alcorcón", Province: "28", Lat: 40.3458, Lon: -3.8249, Population: 170000},
+	{INECode: "28013", Name: "Aranjuez", Province: "28", Lat: 40.0311, Lon: -3.6025, Population: 60000},
+	{INECode: "28058", Name: "Fuenlabrada", Province: "28", Lat: 40.2842, Lon: -3.7942, Population: 192000},
+	{INECode: "28065", Name: "Getafe", Province: "28", Lat: 40.3057, Lon: -3.7329, Population: 185000},
+	{INECode: "28074", Name: "Leganés", Province: "28", Lat: 40.3272, Lon: -3.7635, Population: 187000},
+	{INECode: "28079", Name: "Madrid", Province: "28", Lat: 40.4168, Lon: -3.7038, Population: 3280000},
+	{INECode: "28092", Name: "Móstoles", Province: "28", Lat: 40.3223, Lon: -3.8649, Population: 207000},
+	{INECode: "28106", Name: "Parla", Province: "28", Lat: 40.2360, Lon: -3.7675, Population: 131000},
+	{INECode: "28127", Name: "Rozas de Madrid, Las/Las Rozas", Province: "28", Lat: 40.4929, Lon: -3.8737, Population: 96000},
+	{INECode: "28148", Name: "Torrejón de Ardoz", Province: "28", Lat: 40.4582, Lon: -3.4800, Population: 133000},
+	{INECode: "28903", Name: "Tres Cantos", Province: "28", Lat: 40.6009, Lon: -3.7081, Population: 48000},
+	// other provincial capitals
+	{INECode: "01059", Name: "Vitoria-Gasteiz/Vitoria", Province: "01", Lat: 42.8467, Lon: -2.6716, Population: 255000},
+	{INECode: "03014", Name: "Alicante/Alacant", Province: "03", Lat: 38.3452, Lon: -0.4810, Population: 338000},
+	{INECode: "04013", Name: "Almería", Province: "04", Lat: 36.8340, Lon: -2.4637, Population: 200000},
+	{INECode: "07040", Name: "Palma/Palma de Mallorca", Province: "07", Lat: 39.5696, Lon: 2.6502, Population: 419000},
+	{INECode: "08019", Name: "Barcelona", Province: "08", Lat: 41.3874, Lon: 2.1686, Population: 1640000},
+	{INECode: "11012", Name: "Cádiz", Province: "11", Lat: 36.5271, Lon: -6.2886, Population: 113000},
+	{INECode: "12040", Name: "Castellón de la Plana/Castelló de la Plana", Province: "12", Lat: 39.9864, Lon: -0.0513, Population: 172000},
+	{INECode: "14021", Name: "Córdoba", Province: "14", Lat: 37.8882, Lon: -4.7794, Population: 320000},
+	{INECode: "15030", Name: "A Coruña/La Coruña", Province: "15", Lat: 43.3623, Lon: -8.4115, Population: 247000},
+	{INECode: "17079", Name: "Girona/Gerona", Province: "17", Lat: 41.9794, Lon: 2.8214, Population: 103000},
+	{INECode: "18087", Name: "Granada", Province: "18", Lat: 37.1773, Lon: -3.5986, Population: 228000},
+	{INECode: "20069", Name: "Donostia/San Sebastián", Province: "20", Lat: 43.3183, Lon: -1.9812, Population: 187000},
+	{INECode: "21041", Name: "Huelva", Province: "21", Lat: 37.2614, Lon: -6.9447, Population: 142000},
+	{INECode: "22125", Name: "Huesca", Province: "22", Lat: 42.1362, Lon: -0.4087, Population: 53000},
+	{INECode: "23050", Name: "Jaén", Province: "23", Lat: 37.7796, Lon: -3.7849, Population: 111000},
+	{INECode: "25120", Name: "Lleida/Lérida", Province: "25", Lat: 41.6176, Lon: 0.6200, Population: 140000},
+	{INECode: "26089", Name: "Logroño", Province: "26", Lat: 42.4627, Lon: -2.4450, Population: 151000},
+	{INECode: "27028", Name: "Lugo", Province: "27", Lat: 43.0097, Lon: -7.5568, Population: 97000},
+	{INECode: "29067", Name: "Málaga", Province: "29", Lat: 36.7213, Lon: -4.4214, Population: 580000},
+	{INECode: "30030", Name: "Murcia", Province: "30", Lat: 37.9922, Lon: -1.1307, Population: 462000},
+	{INECode: "31201", Name: "Pamplona/Iruña", Province: "31", Lat: 42.8125, Lon: -1.6458, Population: 203000},
+	{INECode: "32054", Name: "Ourense/Orense", Province: "32", Lat: 42.3358, Lon: -7.8639, Population: 104000},
+	{INECode: "33044", Name: "Oviedo", Province: "33", Lat: 43.3614, Lon: -5.8494, Population: 219000},
+	{INECode: "35016", Name: "Palmas de Gran Canaria, Las/Las Palmas", Province: "35", Lat: 28.1235, Lon: -15.4363, Population: 379000},
+	{INECode: "36038", Name: "Pontevedra", Province: "36", Lat: 42.4310, Lon: -8.6444, Population: 83000},
+	{INECode: "38038", Name: "Santa Cruz de Tenerife", Province: "38", Lat: 28.4636, Lon: -16.2518, Population: 208000},
+	{INECode: "39075", Name: "Santander", Province: "39", Lat: 43.4623, Lon: -3.8100, Population: 172000},
+	{INECode: "41091", Name: "Sevilla", Province: "41", Lat: 37.3891, Lon: -5.9845, Population: 684000},
+	{INECode: "43148", Name: "Tarragona", Province: "43", Lat: 41.1189, Lon: 1.2445, Population: 135000},
+	{INECode: "44216", Name: "Teruel", Province: "44", Lat: 40.3456, Lon: -1.1065, Population: 35500},
+	{INECode: "46250", Name: "Valencia/València", Province: "46", Lat: 39.4699, Lon: -0.3763, Population: 792000},
+	{INECode: "48020", Name: "Bilbao", Province: "48", Lat: 43.2630, Lon: -2.9350, Population: 345000},
+	{INECode: "50297", Name: "Zaragoza", Province: "50", Lat: 41.6488, Lon: -0.8891, Population: 675000},
+	{INECode: "51001", Name: "Ceuta", Province: "51", Lat: 35.8894, Lon: -5.3213, Population: 83000},
+	{INECode: "52001", Name: "Melilla", Province: "52", Lat: 35.2923, Lon: -2.9381, Population: 85000},
+}
+
+// bundledVenues are the usual venues of the agendas, not including the municipality
+var bundledVenues = []Venue{
+	{Name: "Palacio de Fuensalida", INECode: "45168", Lat: 39.8580, Lon: -4.0255},
+	{Name: "Cortes de Castilla-La Mancha", Aliases: []string{"Convento de San Gil"}, INECode: "45168", Lat: 39.8584, Lon: -4.0226},
+	{Name: "Colegio de la Asunción", Aliases: []string{"Sede de la Presidencia de la Junta de Castilla y León"}, INECode: "47186", Lat: 41.6488, Lon: -4.7431},
+	{Name: "Cortes de Castilla y León", INECode: "47186", Lat: 41.6386, Lon: -4.7436},
+	{Name: "Asamblea de Extremadura", INECode: "06083", Lat: 38.9186, Lon: -6.3430},
+	{Name: "Presidencia de la Junta de Extremadura", INECode: "06083", Lat: 38.9166, Lon: -6.3441},
+	{Name: "Real Casa de Correos", Aliases: []string{"Puerta del Sol"}, INECode: "28079", Lat: 40.4166, Lon: -3.7038},
+	{Name: "Asamblea de Madrid", INECode: "28079", Lat: 40.3844, Lon: -3.6574},
+	{Name: "Palacio de la Moncloa", INECode: "28079", Lat: 40.4450, Lon: -3.7360},
+	{Name: "Congreso de los Diputados", INECode: "28079", Lat: 40.4163, Lon: -3.6967},
+	{Name: "Palacio del Senado", INECode: "28079", Lat: 40.4217, Lon: -3.7120},
+}
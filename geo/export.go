@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+// LocatedEvent represents an event with the place of its location
+type LocatedEvent struct {
+	Event models.AgendaEvent
+	Place Place
+}
+
+// Locate returns the events with a place: the one stored when they were geocoded, or the
+// one their locations are resolved to, for the events indexed without coordinates
+func (g *Gazetteer) Locate(events []models.AgendaEvent) []LocatedEvent {
+	located := []LocatedEvent{}
+	for _, event := range events {
+		place, ok := storedPlace(event)
+		if !ok {
+			place, ok = g.Resolve(event.OriginalLocation, event.Region)
+		}
+		if !ok {
+			continue
+		}
+
+		located = append(located, LocatedEvent{Event: event, Place: place})
+	}
+
+	return located
+}
+
+// storedPlace returns the place stored with a geocoded event
+func storedPlace(event models.AgendaEvent) (Place, bool) {
+	if event.Coordinates == nil {
+		return Place{}, false
+	}
+
+	return Place{
+		INECode:      event.INECode,
+		Municipality: event.Municipality,
+		Province:     event.Province,
+		Region:       event.Region,
+		Lat:          event.Coordinates.Lat,
+		Lon:          event.Coordinates.Lon,
+	}, true
+}
+
+// properties returns the properties of a located event, shared by GeoJSON and KML
+func (le LocatedEvent) properties() [][2]string {
+	return [][2]string{
+		{"date", le.Event.Date.Format(time.RFC3339)},
+		{"owner", le.Event.Owner},
+		{"region", le.Event.Region},
+		{"description", le.Event.OriginalDescription},
+		{"location", le.Event.OriginalLocation},
+		{"municipality", le.Place.Municipality},
+		{"province", le.Place.Province},
+		{"ineCode", le.Place.INECode},
+	}
+}
+
+// EventFeatures returns the located events as GeoJSON points
+func EventFeatures(events []LocatedEvent) *FeatureCollection {
+	features := NewFeatureCollection()
+	for _, event := range events {
+		properties := map[string]interface{}{}
+		for _, property := range event.properties() {
+			properties[property[0]] = property[1]
+		}
+
+		features.AddPoint(event.Place.Lat, event.Place.Lon, properties)
+	}
+
+	return features
+}
@@ -0,0 +1,497 @@
+package geo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Province represents a Spanish province
+type Province struct {
+	Code   string
+	Name   string
+	Region string
+}
+
+// Municipality represents a Spanish municipality. The name can include alternative names
+// separated by '/', as "Alicante/Alacant"
+type Municipality struct {
+	INECode    string
+	Name       string
+	Province   string
+	Lat        float64
+	Lon        float64
+	Population int
+}
+
+// Venue represents a known place, as the seat of a regional government
+type Venue struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+	INECode string   `json:"ineCode"`
+	// Lat and Lon of the venue. If not set, the ones of the municipality are used
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Place represents the result of geocoding a location
+type Place struct {
+	INECode      string  `json:"ineCode"`
+	Municipality string  `json:"municipality"`
+	Province     string  `json:"province"`
+	Region       string  `json:"region"`
+	Venue        string  `json:"venue"`
+	Lat          float64 `json:"lat"`
+	Lon          float64 `json:"lon"`
+	Population   int     `json:"population"`
+}
+
+var articleRegexp = regexp.MustCompile(`^(.*),\s*(El|La|Los|Las|L'|A|O|Os|As)$`)
+
+// Gazetteer represents the municipalities, provinces and venues used to geocode locations
+type Gazetteer struct {
+	provinces      map[string]Province
+	municipalities map[string]Municipality
+	venues         []Venue
+	names          []placeName
+	// loaded is set when municipalities are loaded from a file
+	loaded bool
+}
+
+type placeName struct {
+	normalized string
+	ineCode    string
+	venue      int
+}
+
+// NewGazetteer returns the gazetteer with the bundled provinces, municipalities and venues
+func NewGazetteer() *Gazetteer {
+	g := &Gazetteer{
+		provinces:      map[string]Province{},
+		municipalities: map[string]Municipality{},
+	}
+
+	for _, province := range bundledProvinces {
+		g.provinces[province.Code] = province
+	}
+	for _, municipality := range bundledMunicipalities {
+		g.municipalities[municipality.INECode] = municipality
+	}
+	g.venues = append(g.venues, bundledVenues...)
+
+	g.index()
+
+	return g
+}
+
+// LoadMunicipalities adds the municipalities of a CSV file to the gazetteer, replacing the
+// ones with the same INE code. The header row must include the INE code, the name and the
+// coordinates of the municipalities, as "ine,name,lat,lon", and optionally their population.
+// The MUNICIPIOS.csv file of the IGN Nomenclátor Geográfico de Municipios, with the whole
+// INE list, is read as downloaded: Latin-1 encoded, with 11 digit codes and decimal commas
+func (g *Gazetteer) LoadMunicipalities(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := readCSV(file)
+	if err != nil {
+		return err
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[analysis.Normalize(name)] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	ineColumn := column("ine", "ine code", "codigo", "codigo ine", "code", "cod ine")
+	nameColumn := column("name", "nombre", "municipio", "nombre actual")
+	latColumn := column("lat", "latitude", "latitud", "latitud etrs89")
+	lonColumn := column("lon", "lng", "longitude", "longitud", "longitud etrs89")
+	populationColumn := column("population", "poblacion", "habitantes", "poblacion muni")
+	if ineColumn < 0 || nameColumn < 0 || latColumn < 0 || lonColumn < 0 {
+		return fmt.Errorf("the CSV header does not include the INE code, name, lat and lon columns: %v", records[0])
+	}
+
+	for i, record := range records[1:] {
+		if len(record) <= ineColumn || len(record) <= nameColumn || len(record) <= latColumn || len(record) <= lonColumn {
+			continue
+		}
+
+		ineCode := strings.TrimSpace(record[ineColumn])
+		if len(ineCode) > 5 {
+			// the IGN codes add the entity of population to the INE code
+			ineCode = ineCode[:5]
+		}
+
+		municipality := Municipality{
+			INECode: fmt.Sprintf("%05s", ineCode),
+			Name:    strings.TrimSpace(record[nameColumn]),
+		}
+		municipality.Province = municipality.INECode[:2]
+
+		municipality.Lat, err = parseCoordinate(record[latColumn])
+		if err == nil {
+			municipality.Lon, err = parseCoordinate(record[lonColumn])
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: wrong coordinates: %v", i+2, err)
+		}
+
+		if populationColumn >= 0 && populationColumn < len(record) && strings.TrimSpace(record[populationColumn]) != "" {
+			population := strings.ReplaceAll(strings.TrimSpace(record[populationColumn]), ".", "")
+			municipality.Population, err = strconv.Atoi(population)
+			if err != nil {
+				return fmt.Errorf("line %d: wrong population: %v", i+2, err)
+			}
+		}
+
+		g.municipalities[municipality.INECode] = municipality
+	}
+
+	g.loaded = true
+	g.index()
+
+	return nil
+}
+
+// parseCoordinate parses a coordinate in decimal degrees, with either a decimal point or a
+// decimal comma
+func parseCoordinate(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// LoadVenues adds the venues of a JSON file to the gazetteer
+func (g *Gazetteer) LoadVenues(path string) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	venues := []Venue{}
+	err = json.Unmarshal(bytes, &venues)
+	if err != nil {
+		return err
+	}
+
+	g.venues = append(g.venues, venues...)
+	g.index()
+
+	return nil
+}
+
+// index builds the list of names to look for in the locations: venues first, and then the
+// longest names first so that "Villanueva de la Serena" wins over "Villanueva"
+func (g *Gazetteer) index() {
+	g.names = []placeName{}
+
+	for i, venue := range g.venues {
+		for _, name := range append([]string{venue.Name}, venue.Aliases...) {
+			g.names = append(g.names, placeName{normalized: analysis.Normalize(name), venue: i})
+		}
+	}
+
+	for code, municipality := range g.municipalities {
+		for _, name := range strings.Split(municipality.Name, "/") {
+			name = withArticle(name)
+			g.names = append(g.names, placeName{normalized: analysis.Normalize(name), ineCode: code, venue: -1})
+		}
+	}
+
+	sort.SliceStable(g.names, func(i, j int) bool {
+		if (g.names[i].venue >= 0) != (g.names[j].venue >= 0) {
+			return g.names[i].venue >= 0
+		}
+		if len(g.names[i].normalized) != len(g.names[j].normalized) {
+			return len(g.names[i].normalized) > len(g.names[j].normalized)
+		}
+		return g.names[i].ineCode < g.names[j].ineCode
+	})
+}
+
+// Resolve geocodes a location, looking for known venues first and then for names of
+// municipalities. When several municipalities share a name, the one in a province named
+// in the location wins, and then the one in the region
+func (g *Gazetteer) Resolve(location string, region string) (Place, bool) {
+	text := " " + analysis.Normalize(location) + " "
+	if strings.TrimSpace(text) == "" {
+		return Place{}, false
+	}
+
+	venues := true
+	var candidates []Municipality
+	var matched []string
+	for _, name := range g.names {
+		if venues && name.venue < 0 {
+			// "Junta de Castilla y León" is not in León
+			for _, regionName := range regionNames {
+				text = strings.ReplaceAll(text, " "+regionName+" ", " ")
+			}
+			venues = false
+		}
+		if name.normalized == "" || !strings.Contains(text, " "+name.normalized+" ") {
+			continue
+		}
+
+		if name.venue >= 0 {
+			return g.venuePlace(g.venues[name.venue])
+		}
+
+		// "Villanueva" is part of "Villanueva de la Serena"
+		if partOf(name.normalized, matched) {
+			continue
+		}
+
+		matched = append(matched, name.normalized)
+		candidates = append(candidates, g.municipalities[name.ineCode])
+	}
+	if len(candidates) == 0 {
+		return Place{}, false
+	}
+
+	// "Sigüenza (Guadalajara)" is not in Guadalajara
+	if len(candidates) > 1 {
+		filtered := []Municipality{}
+		for i, candidate := range candidates {
+			if !g.isProvince(matched[i]) {
+				filtered = append(filtered, candidate)
+			}
+		}
+		if len(filtered) > 0 {
+			candidates = filtered
+		}
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates {
+		province := g.provinces[candidate.Province]
+		if g.mentions(text, province.Name) {
+			best = candidate
+			break
+		}
+		if province.Region == region && g.provinces[best.Province].Region != region {
+			best = candidate
+		}
+	}
+
+	return g.place(best), true
+}
+
+// GeocodeEvent sets the place of an event from its original location
+func (g *Gazetteer) GeocodeEvent(event *models.AgendaEvent) {
+	place, ok := g.Resolve(event.OriginalLocation, event.Region)
+	if !ok {
+		if event.OriginalLocation != "" {
+			log.WithFields(log.Fields{
+				"location": event.OriginalLocation,
+				"region":   event.Region,
+			}).Debug("Location not found in the gazetteer")
+		}
+		return
+	}
+
+	event.Coordinates = &models.GeoPoint{Lat: place.Lat, Lon: place.Lon}
+	event.INECode = place.INECode
+	event.Municipality = place.Municipality
+	event.Province = place.Province
+}
+
+// Municipality returns the municipality with the INE code
+func (g *Gazetteer) Municipality(ineCode string) (Municipality, bool) {
+	municipality, ok := g.municipalities[ineCode]
+	return municipality, ok
+}
+
+// Capital returns the place of the capital of a region
+func (g *Gazetteer) Capital(region string) (Place, bool) {
+	municipality, ok := g.municipalities[capitals[region]]
+	if !ok {
+		return Place{}, false
+	}
+
+	return g.place(municipality), true
+}
+
+// Places returns the places of the municipalities of a region, sorted by INE code
+func (g *Gazetteer) Places(region string) []Place {
+	places := []Place{}
+	for _, municipality := range g.municipalities {
+		if g.provinces[municipality.Province].Region == region {
+			places = append(places, g.place(municipality))
+		}
+	}
+
+	sort.Slice(places, func(i, j int) bool {
+		return places[i].INECode < places[j].INECode
+	})
+
+	return places
+}
+
+// Complete checks if the gazetteer includes all the municipalities of a region, with their
+// population. Only the main ones are bundled, and for the regions whose number of
+// municipalities is not known, any loaded list is considered complete
+func (g *Gazetteer) Complete(region string) bool {
+	if count, ok := regionMunicipalities[region]; ok {
+		return len(g.Places(region)) >= count
+	}
+
+	return g.loaded
+}
+
+// partOf checks if a normalised name is a word sequence of any of the names
+func partOf(name string, names []string) bool {
+	for _, other := range names {
+		if other != name && strings.Contains(" "+other+" ", " "+name+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isProvince checks if a normalised name is the name of a province
+func (g *Gazetteer) isProvince(name string) bool {
+	for _, province := range g.provinces {
+		if g.mentions(" "+name+" ", province.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mentions checks if a normalised text includes any of the alternative names of a place
+func (g *Gazetteer) mentions(text string, names string) bool {
+	for _, name := range strings.Split(names, "/") {
+		if strings.Contains(text, " "+analysis.Normalize(name)+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Gazetteer) venuePlace(venue Venue) (Place, bool) {
+	municipality, ok := g.municipalities[venue.INECode]
+	if !ok {
+		return Place{}, false
+	}
+
+	place := g.place(municipality)
+	place.Venue = venue.Name
+	if venue.Lat != 0 || venue.Lon != 0 {
+		place.Lat = venue.Lat
+		place.Lon = venue.Lon
+	}
+
+	return place, true
+}
+
+func (g *Gazetteer) place(municipality Municipality) Place {
+	province := g.provinces[municipality.Province]
+
+	return Place{
+		INECode:      municipality.INECode,
+		Municipality: withArticle(strings.Split(municipality.Name, "/")[0]),
+		Province:     strings.Split(province.Name, "/")[0],
+		Region:       province.Region,
+		Lat:          municipality.Lat,
+		Lon:          municipality.Lon,
+		Population:   municipality.Population,
+	}
+}
+
+// withArticle moves the article of the names listed by INE as "Rozas de Madrid, Las" to
+// the beginning
+func withArticle(name string) string {
+	name = strings.TrimSpace(name)
+	if matches := articleRegexp.FindStringSubmatch(name); matches != nil {
+		if strings.HasSuffix(matches[2], "'") {
+			return matches[2] + matches[1]
+		}
+		return matches[2] + " " + matches[1]
+	}
+
+	return name
+}
+
+// readCSV reads CSV records, supporting both comma and semicolon separators, and both UTF-8
+// and Latin-1 encodings
+func readCSV(r io.Reader) ([][]string, error) {
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(bytes)
+	if !utf8.Valid(bytes) {
+		runes := make([]rune, len(bytes))
+		for i, b := range bytes {
+			runes[i] = rune(b)
+		}
+		content = string(runes)
+	}
+	header := content
+	if i := strings.Index(content, "\n"); i >= 0 {
+		header = content[:i]
+	}
+
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	return records, nil
+}
+
+// Distance returns the great-circle distance between two places, in kilometers
+func Distance(a Place, b Place) float64 {
+	const earthRadius = 6371.0
+
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,124 @@
+package geo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestResolve(t *testing.T) {
+	g := NewGazetteer()
+
+	tests := []struct {
+		location string
+		region   string
+		ineCode  string
+	}{
+		{"Toledo", "Castilla-La Mancha", "45168"},
+		{"Sigüenza (Guadalajara)", "Castilla-La Mancha", "19257"},
+		{"Villanueva de la Serena", "Extremadura", "06153"},
+		{"Las Rozas", "Madrid", "28127"},
+		{"Palacio de Fuensalida", "Castilla-La Mancha", "45168"},
+		{"Cortes de Castilla y León", "Castilla-León", "47186"},
+		{"Sede de la Junta de Castilla y León", "Castilla-León", ""},
+		{"", "Madrid", ""},
+	}
+
+	for _, test := range tests {
+		place, ok := g.Resolve(test.location, test.region)
+		if test.ineCode == "" {
+			if ok {
+				t.Errorf("%q resolved to %+v", test.location, place)
+			}
+			continue
+		}
+
+		if !ok || place.INECode != test.ineCode {
+			t.Errorf("%q resolved to %q, want %q", test.location, place.INECode, test.ineCode)
+		}
+	}
+}
+
+func TestLoadMunicipalitiesIGN(t *testing.T) {
+	dir, err := ioutil.TempDir("", "geo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Latin-1, as downloaded from the IGN
+	csv := "COD_INE;PROVINCIA;NOMBRE_ACTUAL;POBLACION_MUNI;LONGITUD_ETRS89;LATITUD_ETRS89\n" +
+		"45001000000;Toledo;Ajofr\xedn;2300;-3,98;39,71\n" +
+		"45002000000;Toledo;Alameda de la Sagra;3600;-3,79;40,01\n"
+	path := filepath.Join(dir, "MUNICIPIOS.csv")
+	if err := ioutil.WriteFile(path, []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGazetteer()
+	if err := g.LoadMunicipalities(path); err != nil {
+		t.Fatal(err)
+	}
+
+	municipality, ok := g.Municipality("45001")
+	if !ok || municipality.Name != "Ajofrín" || municipality.Lat != 39.71 || municipality.Lon != -3.98 || municipality.Population != 2300 {
+		t.Errorf("unexpected municipality %+v", municipality)
+	}
+
+	place, ok := g.Resolve("Visita a Ajofrín", "Castilla-La Mancha")
+	if !ok || place.INECode != "45001" || place.Province != "Toledo" {
+		t.Errorf("unexpected place %+v", place)
+	}
+}
+
+func TestTerritoriesIncomplete(t *testing.T) {
+	g := NewGazetteer()
+	if g.Complete("Castilla-La Mancha") {
+		t.Error("the bundled municipalities are considered complete")
+	}
+
+	events := []models.AgendaEvent{
+		{Owner: "Presidente", Region: "Castilla-La Mancha", OriginalLocation: "Sigüenza", Date: time.Now()},
+	}
+	territories := g.Territories(events, func(time.Time) string { return "all" }, 5000)
+	if len(territories) != 1 {
+		t.Fatalf("unexpected territories %+v", territories)
+	}
+
+	territory := territories[0]
+	if territory.Complete || territory.Rural != 0 || len(territory.NotVisited) != 0 || territory.Located != 1 {
+		t.Errorf("unexpected territory %+v", territory)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	g := NewGazetteer()
+
+	events := []models.AgendaEvent{
+		{
+			ID:               "stored",
+			OriginalLocation: "Toledo",
+			Coordinates:      &models.GeoPoint{Lat: 39.8580, Lon: -4.0255},
+			INECode:          "45168",
+			Municipality:     "Toledo",
+			Province:         "Toledo",
+		},
+		{ID: "resolved", OriginalLocation: "Sigüenza"},
+		{ID: "unknown", OriginalLocation: "Videoconferencia"},
+	}
+
+	located := g.Locate(events)
+	if len(located) != 2 {
+		t.Fatalf("unexpected located events %+v", located)
+	}
+	if place := located[0].Place; place.Lat != 39.8580 || place.Lon != -4.0255 || place.INECode != "45168" {
+		t.Errorf("the stored coordinates were not used: %+v", place)
+	}
+	if place := located[1].Place; place.INECode != "19257" {
+		t.Errorf("unexpected resolved place %+v", place)
+	}
+}
@@ -0,0 +1,46 @@
+package geo
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// FeatureCollection represents a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature represents a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry represents a GeoJSON point
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// NewFeatureCollection returns an empty feature collection
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// AddPoint adds a point feature with the properties. GeoJSON coordinates are longitude first
+func (fc *FeatureCollection) AddPoint(lat float64, lon float64, properties map[string]interface{}) {
+	fc.Features = append(fc.Features, Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: properties,
+	})
+}
+
+// Write writes the feature collection as GeoJSON
+func (fc *FeatureCollection) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(fc)
+}
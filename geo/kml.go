@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type kmlDocument struct {
+	XMLName  xml.Name  `xml:"kml"`
+	XMLNS    string    `xml:"xmlns,attr"`
+	Document kmlFolder `xml:"Document"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string    `xml:"name"`
+	Description  string    `xml:"description"`
+	TimeStamp    kmlWhen   `xml:"TimeStamp"`
+	ExtendedData []kmlData `xml:"ExtendedData>Data"`
+	Point        kmlPoint  `xml:"Point"`
+}
+
+type kmlWhen struct {
+	When string `xml:"when"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes the located events as KML placemarks, with their date as time stamp
+func WriteKML(w io.Writer, name string, events []LocatedEvent) error {
+	doc := kmlDocument{
+		XMLNS:    "http://www.opengis.net/kml/2.2",
+		Document: kmlFolder{Name: name, Placemarks: []kmlPlacemark{}},
+	}
+
+	for _, event := range events {
+		placemark := kmlPlacemark{
+			Name:        fmt.Sprintf("%s - %s", event.Event.Owner, event.Event.Date.Format("2006-01-02 15:04")),
+			Description: event.Event.OriginalDescription,
+			TimeStamp:   kmlWhen{When: event.Event.Date.Format(time.RFC3339)},
+			Point:       kmlPoint{Coordinates: fmt.Sprintf("%f,%f", event.Place.Lon, event.Place.Lat)},
+		}
+		for _, property := range event.properties() {
+			placemark.ExtendedData = append(placemark.ExtendedData, kmlData{Name: property[0], Value: property[1]})
+		}
+
+		doc.Document.Placemarks = append(doc.Document.Placemarks, placemark)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,174 @@
+package geo
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Visit represents the events of an owner in a municipality
+type Visit struct {
+	Place
+	Events int `json:"events"`
+}
+
+// Territory represents where the events of an owner took place in a period
+type Territory struct {
+	Owner  string `json:"owner"`
+	Region string `json:"region"`
+	Period string `json:"period"`
+	Events int    `json:"events"`
+	// Located events, resolved to a municipality
+	Located   int `json:"located"`
+	Capital   int `json:"capital"`
+	Elsewhere int `json:"elsewhere"`
+	// Complete is set when all the municipalities of the region are known. Otherwise, the
+	// rural and urban events and the municipalities not visited are not computed
+	Complete bool `json:"complete"`
+	// Rural and Urban events, only for municipalities with known population
+	Rural     int            `json:"rural"`
+	Urban     int            `json:"urban"`
+	Provinces map[string]int `json:"provinces"`
+	Visits    []Visit        `json:"visits"`
+	// NotVisited municipalities of the region
+	NotVisited []Place `json:"notVisited"`
+}
+
+// Territories returns, per owner and period, where their events took place: in the
+// capital of the region or elsewhere, per province and municipality, and in rural
+// municipalities, with less population than ruralPopulation, or in urban ones, when all
+// the municipalities of the region are known
+func (g *Gazetteer) Territories(events []models.AgendaEvent, period func(time.Time) string, ruralPopulation int) []Territory {
+	territories := map[string]*Territory{}
+	visits := map[string]map[string]*Visit{}
+	keys := []string{}
+
+	for _, event := range events {
+		key := event.Owner + "\x00" + event.Region + "\x00" + period(event.Date)
+		territory, ok := territories[key]
+		if !ok {
+			territory = &Territory{
+				Owner:      event.Owner,
+				Region:     event.Region,
+				Period:     period(event.Date),
+				Complete:   g.Complete(event.Region),
+				Provinces:  map[string]int{},
+				Visits:     []Visit{},
+				NotVisited: []Place{},
+			}
+			territories[key] = territory
+			visits[key] = map[string]*Visit{}
+			keys = append(keys, key)
+		}
+
+		territory.Events++
+
+		place, ok := g.Resolve(event.OriginalLocation, event.Region)
+		if !ok {
+			continue
+		}
+
+		territory.Located++
+		territory.Provinces[place.Province]++
+
+		if capital, ok := g.Capital(event.Region); ok && capital.INECode == place.INECode {
+			territory.Capital++
+		} else {
+			territory.Elsewhere++
+		}
+
+		if territory.Complete && place.Population > 0 {
+			if place.Population < ruralPopulation {
+				territory.Rural++
+			} else {
+				territory.Urban++
+			}
+		}
+
+		visit, ok := visits[key][place.INECode]
+		if !ok {
+			// the municipality, not the venue
+			place.Venue = ""
+			if municipality, found := g.municipalities[place.INECode]; found {
+				place.Lat = municipality.Lat
+				place.Lon = municipality.Lon
+			}
+
+			visit = &Visit{Place: place}
+			visits[key][place.INECode] = visit
+		}
+		visit.Events++
+	}
+
+	result := []Territory{}
+	for _, key := range keys {
+		territory := territories[key]
+
+		for _, place := range g.Places(territory.Region) {
+			if visit, ok := visits[key][place.INECode]; ok {
+				territory.Visits = append(territory.Visits, *visit)
+			} else if territory.Complete {
+				territory.NotVisited = append(territory.NotVisited, place)
+			}
+		}
+
+		// municipalities out of the region
+		for _, visit := range visits[key] {
+			if visit.Region != territory.Region {
+				territory.Visits = append(territory.Visits, *visit)
+			}
+		}
+
+		sort.SliceStable(territory.Visits, func(i, j int) bool {
+			if territory.Visits[i].Events != territory.Visits[j].Events {
+				return territory.Visits[i].Events > territory.Visits[j].Events
+			}
+			return territory.Visits[i].INECode < territory.Visits[j].INECode
+		})
+
+		result = append(result, *territory)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Period != result[j].Period {
+			return result[i].Period < result[j].Period
+		}
+		if result[i].Region != result[j].Region {
+			return result[i].Region < result[j].Region
+		}
+		return result[i].Owner < result[j].Owner
+	})
+
+	return result
+}
+
+// TerritoryFeatures returns the municipalities of the territories as GeoJSON points, with
+// the number of events of the owner in the period, zero for the ones never visited
+func TerritoryFeatures(territories []Territory) *FeatureCollection {
+	features := NewFeatureCollection()
+
+	add := func(territory Territory, place Place, events int) {
+		features.AddPoint(place.Lat, place.Lon, map[string]interface{}{
+			"owner":        territory.Owner,
+			"region":       territory.Region,
+			"period":       territory.Period,
+			"municipality": place.Municipality,
+			"province":     place.Province,
+			"ineCode":      place.INECode,
+			"population":   place.Population,
+			"events":       events,
+		})
+	}
+
+	for _, territory := range territories {
+		for _, visit := range territory.Visits {
+			add(territory, visit.Place, visit.Events)
+		}
+		for _, place := range territory.NotVisited {
+			add(territory, place, 0)
+		}
+	}
+
+	return features
+}
@@ -4,11 +4,14 @@ go 1.13
 
 require (
 	github.com/antchfx/htmlquery v1.2.4
+	github.com/antchfx/xpath v1.2.0
 	github.com/elastic/go-elasticsearch/v7 v7.16.0
 	github.com/gocolly/colly/v2 v2.1.0
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.6
 	go.elastic.co/apm v1.11.0
 	go.elastic.co/apm/module/apmelasticsearch v1.11.0
 	go.elastic.co/apm/module/apmhttp v1.11.0
+	golang.org/x/net v0.0.0-20200602114024-627f9648deb9
 )
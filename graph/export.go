@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+const dateLayout = "2006-01-02"
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph in GraphML format
+func (g Graph) WriteGraphML(w io.Writer) error {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+			{ID: "first", For: "edge", AttrName: "first", AttrType: "string"},
+			{ID: "last", For: "edge", AttrName: "last", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "cansino", EdgeDefault: "undirected"},
+	}
+
+	for _, node := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: node.Label},
+				{Key: "kind", Value: node.Kind},
+			},
+		})
+	}
+	for _, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "weight", Value: strconv.Itoa(edge.Weight)},
+				{Key: "first", Value: edge.First.Format(dateLayout)},
+				{Key: "last", Value: edge.Last.Format(dateLayout)},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	TimeFormat      string           `xml:"timeformat,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string          `xml:"id,attr"`
+	Label     string          `xml:"label,attr"`
+	AttValues []gexfAttrValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Weight int    `xml:"weight,attr"`
+	Start  string `xml:"start,attr"`
+	End    string `xml:"end,attr"`
+}
+
+type gexfAttrValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// WriteGEXF writes the graph in GEXF format, where each edge lasts from the first to the
+// last shared event, so that Gephi's timeline can be used
+func (g Graph) WriteGEXF(w io.Writer) error {
+	doc := gexfDocument{
+		XMLNS:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Graph: gexfGraph{
+			Mode:            "dynamic",
+			DefaultEdgeType: "undirected",
+			TimeFormat:      "date",
+			Attributes: []gexfAttributes{{
+				Class:      "node",
+				Attributes: []gexfAttribute{{ID: "kind", Title: "kind", Type: "string"}},
+			}},
+		},
+	}
+
+	for _, node := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:        node.ID,
+			Label:     node.Label,
+			AttValues: []gexfAttrValue{{For: "kind", Value: node.Kind}},
+		})
+	}
+	for i, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: edge.Source,
+			Target: edge.Target,
+			Weight: edge.Weight,
+			Start:  edge.First.Format(dateLayout),
+			End:    edge.Last.Format(dateLayout),
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// WriteCSV writes the edges of the graph as a CSV edge list, as Gephi imports it
+func (g Graph) WriteCSV(w io.Writer) error {
+	labels := map[string]string{}
+	for _, node := range g.Nodes {
+		labels[node.ID] = node.Label
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"Source", "Target", "Weight", "First", "Last", "SourceLabel", "TargetLabel"})
+	if err != nil {
+		return err
+	}
+
+	for _, edge := range g.Edges {
+		err := cw.Write([]string{
+			edge.Source, edge.Target, strconv.Itoa(edge.Weight),
+			edge.First.Format(dateLayout), edge.Last.Format(dateLayout),
+			labels[edge.Source], labels[edge.Target],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
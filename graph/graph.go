@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/entities"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Kinds of nodes
+const (
+	OrganizationNode = "organization"
+	OwnerNode        = "owner"
+	PersonNode       = "person"
+)
+
+// Node represents an owner, an attendee or an organization
+type Node struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+}
+
+// Edge represents the events shared by two nodes
+type Edge struct {
+	Source string    `json:"source"`
+	Target string    `json:"target"`
+	Weight int       `json:"weight"`
+	First  time.Time `json:"first"`
+	Last   time.Time `json:"last"`
+}
+
+// Graph represents the co-occurrence network of the events
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build returns the co-occurrence network of the events, where owners, attendees and
+// organizations are nodes, and each pair of nodes in the same event is linked by an edge
+// weighted by the number of events they shared. Attendees and organizations are resolved
+// by name with the resolver, as in the entities report, ignoring the IDs stored when the
+// events were indexed, which may be stale after the registry is edited
+func Build(events []models.AgendaEvent, resolver *entities.Resolver) Graph {
+	nodes := map[string]Node{}
+	edges := map[[2]string]*Edge{}
+
+	for _, event := range events {
+		participants := []Node{{
+			ID:    "owner-" + strings.ReplaceAll(analysis.Normalize(event.Region+" "+event.Owner), " ", "-"),
+			Label: event.Owner + " (" + event.Region + ")",
+			Kind:  OwnerNode,
+		}}
+
+		for _, attendee := range event.Attendance {
+			if attendee.FullName == "" {
+				continue
+			}
+
+			id := resolver.Resolve(entities.PersonEntity, attendee.FullName)
+			participants = append(participants, Node{ID: id, Label: attendee.FullName, Kind: PersonNode})
+		}
+
+		for _, organization := range event.Organizations {
+			id := resolver.Resolve(entities.OrganizationEntity, organization.Name)
+			participants = append(participants, Node{ID: id, Label: organization.Name, Kind: OrganizationNode})
+		}
+
+		unique := []Node{}
+		for _, node := range participants {
+			if _, exists := nodes[node.ID]; !exists {
+				if entity, ok := resolver.EntityByID(node.ID); ok {
+					node.Label = entity.Name
+				}
+				nodes[node.ID] = node
+			}
+
+			duplicated := false
+			for _, u := range unique {
+				if u.ID == node.ID {
+					duplicated = true
+					break
+				}
+			}
+			if !duplicated {
+				unique = append(unique, node)
+			}
+		}
+
+		for i := 0; i < len(unique); i++ {
+			for j := i + 1; j < len(unique); j++ {
+				key := [2]string{unique[i].ID, unique[j].ID}
+				if key[0] > key[1] {
+					key[0], key[1] = key[1], key[0]
+				}
+
+				edge, ok := edges[key]
+				if !ok {
+					edge = &Edge{Source: key[0], Target: key[1], First: event.Date, Last: event.Date}
+					edges[key] = edge
+				}
+
+				edge.Weight++
+				if event.Date.Before(edge.First) {
+					edge.First = event.Date
+				}
+				if event.Date.After(edge.Last) {
+					edge.Last = event.Date
+				}
+			}
+		}
+	}
+
+	g := Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for _, node := range nodes {
+		g.Nodes = append(g.Nodes, node)
+	}
+	for _, edge := range edges {
+		g.Edges = append(g.Edges, *edge)
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].Source != g.Edges[j].Source {
+			return g.Edges[i].Source < g.Edges[j].Source
+		}
+		return g.Edges[i].Target < g.Edges[j].Target
+	})
+
+	return g
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/entities"
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestBuild(t *testing.T) {
+	registry := []entities.Entity{
+		{ID: "person-garamendi", Kind: entities.PersonEntity, Name: "Antonio Garamendi"},
+		{ID: "org-ceoe", Kind: entities.OrganizationEntity, Name: "CEOE"},
+	}
+	resolver := entities.NewResolver(registry, entities.Overrides{}, 0.9)
+
+	first := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, 0)
+	events := []models.AgendaEvent{
+		{
+			Date:   first,
+			Owner:  "Presidente",
+			Region: "Castilla-La Mancha",
+			// stored before the registry was edited
+			Attendance:    []models.Attendee{{ID: "person-stale", FullName: "D. Antonio Garamendi"}},
+			Organizations: []models.Organization{{ID: "org-stale", Name: "CEOE"}},
+		},
+		{
+			Date:          last,
+			Owner:         "Presidente",
+			Region:        "Castilla-La Mancha",
+			Attendance:    []models.Attendee{{FullName: "Antonio Garamendi"}, {FullName: "Antonio Garamendi"}},
+			Organizations: []models.Organization{{Name: "CEOE"}},
+		},
+	}
+
+	g := Build(events, resolver)
+
+	if len(g.Nodes) != 3 {
+		t.Fatalf("unexpected nodes %+v", g.Nodes)
+	}
+	expected := []Node{
+		{ID: "org-ceoe", Label: "CEOE", Kind: OrganizationNode},
+		{ID: "owner-castilla-la-mancha-presidente", Label: "Presidente (Castilla-La Mancha)", Kind: OwnerNode},
+		{ID: "person-garamendi", Label: "Antonio Garamendi", Kind: PersonNode},
+	}
+	for i, node := range expected {
+		if g.Nodes[i] != node {
+			t.Errorf("expected node %+v, got %+v", node, g.Nodes[i])
+		}
+	}
+
+	if len(g.Edges) != 3 {
+		t.Fatalf("unexpected edges %+v", g.Edges)
+	}
+	for _, edge := range g.Edges {
+		if edge.Weight != 2 || !edge.First.Equal(first) || !edge.Last.Equal(last) {
+			t.Errorf("unexpected edge %+v", edge)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package holidays tells the national, regional and local public holidays of the regions
+package holidays
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/ical"
+)
+
+// Scopes of the holidays. Holidays with the None scope remove built-in ones, as the ones
+// moved to Monday
+const (
+	National = "national"
+	Regional = "regional"
+	Local    = "local"
+	None     = "none"
+)
+
+const dayLayout = "2006-01-02"
+
+// Holiday represents a public holiday
+type Holiday struct {
+	Date  time.Time `json:"date"`
+	Name  string    `json:"name"`
+	Scope string    `json:"scope"`
+}
+
+type rule struct {
+	name  string
+	scope string
+	date  func(year int) time.Time
+}
+
+func fixed(month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// fromEaster returns the day at an offset from Easter Sunday
+func fromEaster(offset int) func(int) time.Time {
+	return func(year int) time.Time {
+		return Easter(year).AddDate(0, 0, offset)
+	}
+}
+
+// Easter returns the Easter Sunday of a year, in the Gregorian calendar
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+var nationalRules = []rule{
+	{"Año Nuevo", National, fixed(time.January, 1)},
+	{"Epifanía del Señor", National, fixed(time.January, 6)},
+	{"Viernes Santo", National, fromEaster(-2)},
+	{"Fiesta del Trabajo", National, fixed(time.May, 1)},
+	{"Asunción de la Virgen", National, fixed(time.August, 15)},
+	{"Fiesta Nacional de España", National, fixed(time.October, 12)},
+	{"Todos los Santos", National, fixed(time.November, 1)},
+	{"Día de la Constitución", National, fixed(time.December, 6)},
+	{"Inmaculada Concepción", National, fixed(time.December, 8)},
+	{"Natividad del Señor", National, fixed(time.December, 25)},
+}
+
+// regionalRules are the usual regional holidays. The ones of a particular year, as the
+// holidays moved from Sunday to Monday, can be set with an overrides file
+var regionalRules = map[string][]rule{
+	"Castilla-La Mancha": {
+		{"Jueves Santo", Regional, fromEaster(-3)},
+		{"Día de Castilla-La Mancha", Regional, fixed(time.May, 31)},
+	},
+	"Castilla-León": {
+		{"Jueves Santo", Regional, fromEaster(-3)},
+		{"Fiesta de Castilla y León", Regional, fixed(time.April, 23)},
+	},
+	"Extremadura": {
+		{"Jueves Santo", Regional, fromEaster(-3)},
+		{"Día de Extremadura", Regional, fixed(time.September, 8)},
+	},
+	"Madrid": {
+		{"Jueves Santo", Regional, fromEaster(-3)},
+		{"Fiesta de la Comunidad de Madrid", Regional, fixed(time.May, 2)},
+	},
+}
+
+// Calendar represents the holidays of a region
+type Calendar struct {
+	Region    string
+	rules     []rule
+	overrides map[string]Holiday
+}
+
+// NewCalendar returns the calendar of a region, with its built-in national and regional
+// holidays
+func NewCalendar(region string) *Calendar {
+	return &Calendar{
+		Region:    region,
+		rules:     append(append([]rule{}, nationalRules...), regionalRules[region]...),
+		overrides: map[string]Holiday{},
+	}
+}
+
+// Add adds a holiday to the calendar, replacing the one of the same day, if any
+func (c *Calendar) Add(holiday Holiday) {
+	holiday.Date = day(holiday.Date)
+	c.overrides[holiday.Date.Format(dayLayout)] = holiday
+}
+
+// Holiday returns the holiday of a day, if any
+func (c *Calendar) Holiday(date time.Time) (Holiday, bool) {
+	if c == nil {
+		return Holiday{}, false
+	}
+
+	date = day(date)
+	if holiday, ok := c.overrides[date.Format(dayLayout)]; ok {
+		return holiday, holiday.Scope != None
+	}
+
+	for _, r := range c.rules {
+		if r.date(date.Year()).Equal(date) {
+			return Holiday{Date: date, Name: r.name, Scope: r.scope}, true
+		}
+	}
+
+	return Holiday{}, false
+}
+
+// Holidays returns the holidays from the first to the last day, both included
+func (c *Calendar) Holidays(from time.Time, to time.Time) []Holiday {
+	holidays := []Holiday{}
+	for date := day(from); !date.After(day(to)); date = date.AddDate(0, 0, 1) {
+		if holiday, ok := c.Holiday(date); ok {
+			holidays = append(holidays, holiday)
+		}
+	}
+
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+
+	return holidays
+}
+
+// Load adds the holidays of an ICS or CSV file, based on its extension, to the calendar.
+// ICS events are local holidays, unless they are cancelled, removing the holiday of the
+// day. CSV files have a header row with the date (yyyy-MM-dd) and the name of the
+// holidays, and optionally their scope and region. Rows of other regions are ignored
+func (c *Calendar) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		events, err := ical.Parse(file, time.UTC)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			scope := Local
+			if event.Status == "CANCELLED" {
+				scope = None
+			}
+
+			c.Add(Holiday{Date: event.Start, Name: event.Summary, Scope: scope})
+		}
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		columns := map[string]int{}
+		for i, name := range records[0] {
+			columns[analysis.Normalize(name)] = i
+		}
+		value := func(record []string, names ...string) string {
+			for _, name := range names {
+				if i, ok := columns[name]; ok && i < len(record) {
+					return strings.TrimSpace(record[i])
+				}
+			}
+			return ""
+		}
+
+		if _, ok := columns["date"]; !ok {
+			if _, ok := columns["fecha"]; !ok {
+				return fmt.Errorf("the CSV header does not include the date column: %v", records[0])
+			}
+		}
+
+		for i, record := range records[1:] {
+			region := value(record, "region", "comunidad autonoma")
+			if region != "" && region != c.Region {
+				continue
+			}
+
+			date, err := time.Parse(dayLayout, value(record, "date", "fecha"))
+			if err != nil {
+				return fmt.Errorf("line %d: wrong date. Please use yyyy-MM-dd", i+2)
+			}
+
+			scope := value(record, "scope", "ambito")
+			if scope == "" {
+				scope = Local
+			}
+
+			c.Add(Holiday{Date: date, Name: value(record, "name", "nombre", "festividad"), Scope: scope})
+		}
+	default:
+		return fmt.Errorf("unsupported holidays file %s. Please use ICS or CSV", path)
+	}
+
+	return nil
+}
+
+// day returns the midnight of the day of a date, in UTC
+func day(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
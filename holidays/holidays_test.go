@@ -0,0 +1,123 @@
+package holidays
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	tests := map[int]time.Time{
+		2000: date(2000, time.April, 23),
+		2019: date(2019, time.April, 21),
+		2020: date(2020, time.April, 12),
+		2021: date(2021, time.April, 4),
+		2024: date(2024, time.March, 31),
+		2038: date(2038, time.April, 25),
+	}
+
+	for year, expected := range tests {
+		if easter := Easter(year); !easter.Equal(expected) {
+			t.Errorf("Easter(%d) = %v, want %v", year, easter, expected)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	tests := []struct {
+		region string
+		date   time.Time
+		name   string
+		scope  string
+	}{
+		{"Castilla-La Mancha", date(2020, time.January, 6), "Epifanía del Señor", National},
+		{"Castilla-La Mancha", date(2020, time.April, 9), "Jueves Santo", Regional},
+		{"Castilla-La Mancha", date(2020, time.April, 10), "Viernes Santo", National},
+		{"Castilla-La Mancha", date(2020, time.May, 31), "Día de Castilla-La Mancha", Regional},
+		{"Castilla-León", date(2020, time.April, 23), "Fiesta de Castilla y León", Regional},
+		{"Extremadura", date(2020, time.September, 8), "Día de Extremadura", Regional},
+		{"Madrid", date(2021, time.April, 1), "Jueves Santo", Regional},
+		{"Madrid", date(2020, time.May, 2), "Fiesta de la Comunidad de Madrid", Regional},
+		{"Madrid", date(2020, time.May, 31), "", ""},
+		{"Castilla-La Mancha", date(2020, time.May, 2), "", ""},
+		{"Aragón", date(2020, time.April, 9), "", ""},
+		{"Aragón", date(2020, time.December, 25), "Natividad del Señor", National},
+	}
+
+	for _, test := range tests {
+		holiday, ok := NewCalendar(test.region).Holiday(test.date.Add(10 * time.Hour))
+		if ok != (test.name != "") || holiday.Name != test.name || holiday.Scope != test.scope {
+			t.Errorf("%s %s: unexpected holiday %+v", test.region, test.date.Format(dayLayout), holiday)
+		}
+	}
+
+	holidays := NewCalendar("Madrid").Holidays(date(2020, time.April, 1), date(2020, time.May, 31))
+	if len(holidays) != 4 || holidays[0].Name != "Jueves Santo" || holidays[3].Name != "Fiesta de la Comunidad de Madrid" {
+		t.Errorf("unexpected holidays %+v", holidays)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	csvPath := filepath.Join(dir, "holidays.csv")
+	err = ioutil.WriteFile(csvPath, []byte("fecha,nombre,ámbito,región\n"+
+		"2021-05-02,Fiesta de la Comunidad de Madrid,none,Madrid\n"+
+		"2021-05-03,Fiesta de la Comunidad de Madrid (trasladada),regional,Madrid\n"+
+		"2021-05-15,San Isidro,,\n"+
+		"2021-05-31,Día de Castilla-La Mancha,regional,Castilla-La Mancha\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	icsPath := filepath.Join(dir, "holidays.ics")
+	err = ioutil.WriteFile(icsPath, []byte("BEGIN:VCALENDAR\r\n"+
+		"BEGIN:VEVENT\r\nSUMMARY:Virgen de la Almudena\r\nDTSTART;VALUE=DATE:20211109\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nSUMMARY:Todos los Santos\r\nSTATUS:CANCELLED\r\nDTSTART;VALUE=DATE:20211101\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calendar := NewCalendar("Madrid")
+	for _, path := range []string{csvPath, icsPath} {
+		if err := calendar.Load(path); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		date  time.Time
+		name  string
+		scope string
+	}{
+		{date(2021, time.May, 2), "", ""},
+		{date(2021, time.May, 3), "Fiesta de la Comunidad de Madrid (trasladada)", Regional},
+		{date(2021, time.May, 15), "San Isidro", Local},
+		{date(2021, time.May, 31), "", ""},
+		{date(2021, time.November, 9), "Virgen de la Almudena", Local},
+		{date(2021, time.November, 1), "", ""},
+		{date(2021, time.December, 25), "Natividad del Señor", National},
+	}
+
+	for _, test := range tests {
+		holiday, ok := calendar.Holiday(test.date)
+		if ok != (test.name != "") || (ok && (holiday.Name != test.name || holiday.Scope != test.scope)) {
+			t.Errorf("%s: unexpected holiday %+v, %v", test.date.Format(dayLayout), holiday, ok)
+		}
+	}
+
+	if err := calendar.Load(filepath.Join(dir, "holidays.txt")); err == nil {
+		t.Error("expected an error for the unsupported file")
+	}
+}
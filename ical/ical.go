@@ -0,0 +1,274 @@
+// Package ical reads the events of iCalendar (RFC 5545) files
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Event represents a VEVENT of a calendar
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// AllDay events have dates without time
+	AllDay     bool
+	Organizer  Attendee
+	Attendees  []Attendee
+	Categories []string
+	// RRule is the recurrence rule of the event, as "FREQ=WEEKLY;BYDAY=MO", whose
+	// occurrences are expanded with Occurrences
+	RRule string
+	// ExDates are the starts of the occurrences excluded from the recurrence rule
+	ExDates []time.Time
+	// RecurrenceID is the start of the occurrence of a recurring event, with the same UID,
+	// replaced by this event, if any
+	RecurrenceID time.Time
+}
+
+// Attendee represents the organizer or an attendee of an event, with its common name, its
+// email and its role, as "REQ-PARTICIPANT"
+type Attendee struct {
+	Name  string
+	Email string
+	Role  string
+}
+
+// Parse reads the events of a calendar. Times without time zone are in the location, as the
+// ones of unknown time zones without VTIMEZONE. Events with wrong times are discarded
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{loc: loc, zones: timezones(lines), unknown: map[string]bool{}}
+
+	events := []Event{}
+	var event *Event
+	var invalid error
+	for i, line := range lines {
+		name, params, value := property(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+			invalid = nil
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", i+1)
+			}
+			if invalid != nil {
+				log.WithFields(log.Fields{
+					"uid":   event.UID,
+					"error": invalid,
+				}).Warn("Cannot parse the time of the event. Discarding it")
+			} else {
+				events = append(events, *event)
+			}
+			event = nil
+		case event == nil:
+			continue
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			event.Description = unescape(value)
+		case name == "LOCATION":
+			event.Location = unescape(value)
+		case name == "STATUS":
+			event.Status = strings.ToUpper(value)
+		case name == "URL":
+			event.URL = value
+		case name == "RRULE":
+			event.RRule = value
+		case name == "ORGANIZER":
+			event.Organizer = attendee(params, value)
+		case name == "ATTENDEE":
+			event.Attendees = append(event.Attendees, attendee(params, value))
+		case name == "CATEGORIES":
+			for _, category := range splitList(value) {
+				if category = strings.TrimSpace(unescape(category)); category != "" {
+					event.Categories = append(event.Categories, category)
+				}
+			}
+		case name == "EXDATE":
+			for _, exdate := range strings.Split(value, ",") {
+				date, _, err := p.parseTime(exdate, params)
+				if err != nil {
+					invalid = fmt.Errorf("line %d: %v", i+1, err)
+					break
+				}
+
+				event.ExDates = append(event.ExDates, date)
+			}
+		case name == "DTSTART" || name == "DTEND" || name == "RECURRENCE-ID":
+			date, allDay, err := p.parseTime(value, params)
+			if err != nil {
+				invalid = fmt.Errorf("line %d: %v", i+1, err)
+				continue
+			}
+
+			switch name {
+			case "DTSTART":
+				event.Start = date
+				event.AllDay = allDay
+			case "DTEND":
+				event.End = date
+			default:
+				event.RecurrenceID = date
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// unfold joins the lines folded with a leading space or tab
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// property splits a content line, as "DTSTART;TZID=Europe/Madrid:20200302T100000", into
+// its name, its parameters and its value. The quoted values of the parameters may have
+// colons and semicolons, as in `ORGANIZER;SENT-BY="mailto:ana@example.com":mailto:...`
+func property(line string) (string, map[string]string, string) {
+	parts := []string{}
+	value := ""
+	found := false
+	quoted := false
+	start := 0
+	for i := 0; i < len(line) && !found; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';', ':':
+			if quoted {
+				continue
+			}
+
+			parts = append(parts, line[start:i])
+			start = i + 1
+			if line[i] == ':' {
+				value = line[i+1:]
+				found = true
+			}
+		}
+	}
+	if !found {
+		return strings.ToUpper(line), map[string]string{}, ""
+	}
+
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if eq := strings.Index(param, "="); eq >= 0 {
+			params[strings.ToUpper(param[:eq])] = strings.Trim(param[eq+1:], `"`)
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, value
+}
+
+// parser reads the times of a calendar with the time zones of its VTIMEZONE blocks
+type parser struct {
+	loc     *time.Location
+	zones   map[string]*zone
+	unknown map[string]bool
+}
+
+// parseTime parses a date or a time, in UTC, in its time zone or in the location. The time
+// zones are the ones of the time zone database, as "Europe/Madrid", or the VTIMEZONE blocks
+// of the calendar, as the Windows time zones of Outlook, as "Romance Standard Time"
+func (p *parser) parseTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		date, err := time.ParseInLocation("20060102", value, p.loc)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		date, err := time.Parse("20060102T150405Z", value)
+		return date, false, err
+	}
+
+	wall, err := time.Parse("20060102T150405", value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	loc := p.loc
+	if tzid, ok := params["TZID"]; ok {
+		if zone, ok := p.zones[tzid]; ok {
+			return zone.in(wall), false, nil
+		}
+
+		tzLoc, err := time.LoadLocation(strings.TrimPrefix(tzid, "/"))
+		if err == nil {
+			loc = tzLoc
+		} else if !p.unknown[tzid] {
+			p.unknown[tzid] = true
+			log.WithFields(log.Fields{
+				"tzid":     tzid,
+				"location": p.loc,
+			}).Warn("Unknown time zone without VTIMEZONE. Using the default location")
+		}
+	}
+
+	y, m, d := wall.Date()
+	h, min, sec := wall.Clock()
+	return time.Date(y, m, d, h, min, sec, 0, loc), false, nil
+}
+
+// attendee reads an organizer or an attendee, as "ATTENDEE;CN=Ana;ROLE=CHAIR:mailto:ana@example.com"
+func attendee(params map[string]string, value string) Attendee {
+	email := value
+	if strings.HasPrefix(strings.ToLower(email), "mailto:") {
+		email = email[len("mailto:"):]
+	}
+
+	return Attendee{Name: params["CN"], Email: email, Role: strings.ToUpper(params["ROLE"])}
+}
+
+// splitList splits a list of text values at the commas not escaped
+func splitList(value string) []string {
+	values := []string{}
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+		} else if value[i] == ',' {
+			values = append(values, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(values, value[start:])
+}
+
+// unescape replaces the escaped characters of the text values
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
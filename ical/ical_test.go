@@ -0,0 +1,199 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1@example.com\r\n" +
+	"SUMMARY:Reunión con el Consejo de Gobierno\\, en sesión ordinaria\r\n" +
+	"DESCRIPTION:Primera línea\\nsegunda lí\r\n" +
+	" nea\r\n" +
+	"LOCATION:Palacio de Fuensalida\\; Toledo\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20200302T100000\r\n" +
+	"DTEND;TZID=Europe/Madrid:20200302T120000\r\n" +
+	"ORGANIZER;CN=Ana García:mailto:ana@example.com\r\n" +
+	"ATTENDEE;CN=Luis Pérez;ROLE=REQ-PARTICIPANT:mailto:luis@example.com\r\n" +
+	"CATEGORIES:Sanidad,Educación\\, Cultura\r\n" +
+	"STATUS:confirmed\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2@example.com\r\n" +
+	"SUMMARY:Visita institucional\r\n" +
+	"DTSTART;VALUE=DATE:20200303\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3@example.com\r\n" +
+	"SUMMARY:Videoconferencia\r\n" +
+	"DTSTART:20200304T090000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := Parse(strings.NewReader(calendar), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	meeting := events[0]
+	if meeting.Summary != "Reunión con el Consejo de Gobierno, en sesión ordinaria" ||
+		meeting.Description != "Primera línea\nsegunda línea" ||
+		meeting.Location != "Palacio de Fuensalida; Toledo" || meeting.Status != "CONFIRMED" {
+		t.Errorf("unexpected texts %+v", meeting)
+	}
+	if !meeting.Start.Equal(time.Date(2020, 3, 2, 10, 0, 0, 0, loc)) || !meeting.End.Equal(time.Date(2020, 3, 2, 12, 0, 0, 0, loc)) || meeting.AllDay {
+		t.Errorf("unexpected times %v - %v", meeting.Start, meeting.End)
+	}
+	if meeting.Organizer != (Attendee{Name: "Ana García", Email: "ana@example.com"}) {
+		t.Errorf("unexpected organizer %+v", meeting.Organizer)
+	}
+	if len(meeting.Attendees) != 1 || meeting.Attendees[0] != (Attendee{Name: "Luis Pérez", Email: "luis@example.com", Role: "REQ-PARTICIPANT"}) {
+		t.Errorf("unexpected attendees %+v", meeting.Attendees)
+	}
+	if len(meeting.Categories) != 2 || meeting.Categories[1] != "Educación, Cultura" {
+		t.Errorf("unexpected categories %v", meeting.Categories)
+	}
+
+	visit := events[1]
+	if !visit.AllDay || !visit.Start.Equal(time.Date(2020, 3, 3, 0, 0, 0, 0, loc)) {
+		t.Errorf("unexpected all-day event %+v", visit)
+	}
+
+	call := events[2]
+	if !call.Start.Equal(time.Date(2020, 3, 4, 10, 0, 0, 0, loc)) {
+		t.Errorf("unexpected UTC time %v", call.Start)
+	}
+}
+
+const outlook = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Romance Standard Time\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:16010101T030000\r\n" +
+	"TZOFFSETFROM:+0200\r\n" +
+	"TZOFFSETTO:+0100\r\n" +
+	"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10\r\n" +
+	"END:STANDARD\r\n" +
+	"BEGIN:DAYLIGHT\r\n" +
+	"DTSTART:16010101T020000\r\n" +
+	"TZOFFSETFROM:+0100\r\n" +
+	"TZOFFSETTO:+0200\r\n" +
+	"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3\r\n" +
+	"END:DAYLIGHT\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:winter\r\n" +
+	"DTSTART;TZID=Romance Standard Time:20200302T100000\r\n" +
+	"ORGANIZER;CN=\"García, Ana\";SENT-BY=\"mailto:secretaria@example.com\":mailto:ana@example.com\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:summer\r\n" +
+	"DTSTART;TZID=Romance Standard Time:20200629T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:unknown\r\n" +
+	"DTSTART;TZID=Hora de Madrid:20200701T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:wrong\r\n" +
+	"DTSTART;TZID=Europe/Madrid:2020-07-02 10:00\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseTimeZones(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := Parse(strings.NewReader(outlook), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	expected := []time.Time{
+		time.Date(2020, 3, 2, 10, 0, 0, 0, loc),
+		time.Date(2020, 6, 29, 10, 0, 0, 0, loc),
+		time.Date(2020, 7, 1, 10, 0, 0, 0, loc),
+	}
+	for i, event := range events {
+		if !event.Start.Equal(expected[i]) {
+			t.Errorf("%s: expected %v, got %v", event.UID, expected[i], event.Start)
+		}
+	}
+
+	if events[0].Organizer != (Attendee{Name: "García, Ana", Email: "ana@example.com"}) {
+		t.Errorf("unexpected organizer %+v", events[0].Organizer)
+	}
+	if events[0].RRule != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("unexpected recurrence rule %q", events[0].RRule)
+	}
+}
+
+func TestProperty(t *testing.T) {
+	tests := []struct {
+		line   string
+		name   string
+		params map[string]string
+		value  string
+	}{
+		{"BEGIN:VEVENT", "BEGIN", map[string]string{}, "VEVENT"},
+		{"dtstart;tzid=Europe/Madrid:20200302T100000", "DTSTART", map[string]string{"TZID": "Europe/Madrid"}, "20200302T100000"},
+		{"DESCRIPTION:Hora: 10:00; lugar", "DESCRIPTION", map[string]string{}, "Hora: 10:00; lugar"},
+		{`ORGANIZER;SENT-BY="mailto:x@y":mailto:a@b`, "ORGANIZER", map[string]string{"SENT-BY": "mailto:x@y"}, "mailto:a@b"},
+		{`ATTENDEE;CN="Pérez; Luis";ROLE=CHAIR:mailto:luis@example.com`, "ATTENDEE", map[string]string{"CN": "Pérez; Luis", "ROLE": "CHAIR"}, "mailto:luis@example.com"},
+	}
+
+	for _, test := range tests {
+		name, params, value := property(test.line)
+		if name != test.name || value != test.value || len(params) != len(test.params) {
+			t.Errorf("%s: unexpected property %s %v %s", test.line, name, params, value)
+			continue
+		}
+		for key, expected := range test.params {
+			if params[key] != expected {
+				t.Errorf("%s: expected %s=%q, got %q", test.line, key, expected, params[key])
+			}
+		}
+	}
+}
+
+func TestObservanceOnset(t *testing.T) {
+	tests := []struct {
+		rule  string
+		year  int
+		onset time.Time
+	}{
+		{"FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3", 2020, time.Date(2020, 3, 29, 2, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10", 2021, time.Date(2021, 10, 31, 2, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYDAY=2SU;BYMONTH=3", 2020, time.Date(2020, 3, 8, 2, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYDAY=SU;BYMONTH=11", 2020, time.Date(2020, 11, 1, 2, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYMONTHDAY=21;BYMONTH=3", 2020, time.Date(2020, 3, 21, 2, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		o := &observance{start: time.Date(1601, 1, 1, 2, 0, 0, 0, time.UTC)}
+		o.setRule(test.rule)
+
+		onset, ok := o.onset(test.year)
+		if !ok || !onset.Equal(test.onset) {
+			t.Errorf("%s: expected %v, got %v", test.rule, test.onset, onset)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package ical
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rule represents a daily, weekly or monthly recurrence rule, as "FREQ=WEEKLY;BYDAY=MO,WE"
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	// untilDate is set if UNTIL is a date, so that the occurrences of its day are included
+	untilDate bool
+	// byDay are the weekdays of the occurrences, with their ordinal in the month, negative
+	// from its end, or zero for every weekday of the period
+	byDay      []ordinalWeekday
+	byMonthDay []int
+	weekStart  time.Weekday
+}
+
+type ordinalWeekday struct {
+	week    int
+	weekday time.Weekday
+}
+
+// maxPeriods limits the periods of a rule expanded, as a safeguard against rules without
+// occurrences, as the ones of the 31st of February
+const maxPeriods = 100000
+
+// Occurrences returns the occurrences of the event starting from a time until another one,
+// excluded: the event itself if it is not recurring, or the occurrences of its recurrence
+// rule but the excluded ones, with the duration of the event. Only daily, weekly and monthly
+// rules with INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and WKST are supported
+func (e Event) Occurrences(from time.Time, until time.Time) ([]Event, error) {
+	if e.RRule == "" {
+		if e.Start.Before(from) || !e.Start.Before(until) {
+			return nil, nil
+		}
+
+		return []Event{e}, nil
+	}
+
+	r, err := parseRule(e.RRule, e.Start.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []Event{}
+	r.each(e.Start, until, func(start time.Time) {
+		if start.Before(from) || e.excluded(start) {
+			return
+		}
+
+		occurrence := e
+		occurrence.Start = start
+		if !e.End.IsZero() {
+			if e.AllDay {
+				days := int(math.Round(e.End.Sub(e.Start).Hours() / 24))
+				occurrence.End = start.AddDate(0, 0, days)
+			} else {
+				occurrence.End = start.Add(e.End.Sub(e.Start))
+			}
+		}
+		occurrences = append(occurrences, occurrence)
+	})
+
+	return occurrences, nil
+}
+
+// excluded checks if an occurrence of the event is one of its excluded dates
+func (e Event) excluded(start time.Time) bool {
+	for _, exdate := range e.ExDates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseRule parses a recurrence rule, with the dates of its UNTIL part in the location
+func parseRule(rrule string, loc *time.Location) (*rule, error) {
+	r := &rule{interval: 1, weekStart: time.Monday}
+
+	for _, part := range strings.Split(rrule, ";") {
+		eq := strings.Index(part, "=")
+		if eq < 0 {
+			continue
+		}
+
+		name := strings.ToUpper(part[:eq])
+		value := strings.ToUpper(part[eq+1:])
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.freq = value
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("not positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && r.count < 1 {
+				err = fmt.Errorf("not positive")
+			}
+		case "UNTIL":
+			err = r.setUntil(value, loc)
+		case "BYDAY":
+			err = r.setByDay(value)
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, convErr := strconv.Atoi(day)
+				if convErr != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					err = fmt.Errorf("wrong day of the month %q", day)
+					break
+				}
+
+				r.byMonthDay = append(r.byMonthDay, monthDay)
+			}
+		case "WKST":
+			weekday, ok := weekdays[value]
+			if !ok {
+				err = fmt.Errorf("wrong weekday")
+			}
+			r.weekStart = weekday
+		default:
+			return nil, fmt.Errorf("unsupported part %s of the recurrence rule %q", name, rrule)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("wrong %s of the recurrence rule: %v", name, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY":
+		if len(r.byMonthDay) > 0 {
+			return nil, fmt.Errorf("unsupported BYMONTHDAY of the %s recurrence rule", r.freq)
+		}
+		for _, day := range r.byDay {
+			if day.week != 0 {
+				return nil, fmt.Errorf("unsupported ordinal weekdays of the %s recurrence rule", r.freq)
+			}
+		}
+	case "MONTHLY":
+		if len(r.byMonthDay) > 0 && len(r.byDay) > 0 {
+			return nil, fmt.Errorf("unsupported BYMONTHDAY and BYDAY of the same recurrence rule")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported frequency %q of the recurrence rule", r.freq)
+	}
+
+	return r, nil
+}
+
+// setUntil sets the last occurrence of the rule, a date or a time, in UTC or in the location
+func (r *rule) setUntil(value string, loc *time.Location) error {
+	var err error
+	switch {
+	case len(value) == 8:
+		r.until, err = time.ParseInLocation("20060102", value, loc)
+		r.untilDate = true
+	case strings.HasSuffix(value, "Z"):
+		r.until, err = time.Parse("20060102T150405Z", value)
+	default:
+		r.until, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+
+	return err
+}
+
+// setByDay sets the weekdays of the rule, as "MO,WE" or "1MO,-1FR"
+func (r *rule) setByDay(value string) error {
+	for _, day := range strings.Split(value, ",") {
+		if len(day) < 2 {
+			return fmt.Errorf("wrong weekday %q", day)
+		}
+
+		weekday, ok := weekdays[day[len(day)-2:]]
+		if !ok {
+			return fmt.Errorf("wrong weekday %q", day)
+		}
+
+		week := 0
+		if ordinal := day[:len(day)-2]; ordinal != "" {
+			var err error
+			week, err = strconv.Atoi(ordinal)
+			if err != nil || week == 0 || week < -5 || week > 5 {
+				return fmt.Errorf("wrong weekday %q", day)
+			}
+		}
+
+		r.byDay = append(r.byDay, ordinalWeekday{week: week, weekday: weekday})
+	}
+
+	return nil
+}
+
+// each calls a function with the starts of the occurrences of the rule before a time, in
+// order: the start of the event, which is always its first occurrence, and the ones after it
+func (r *rule) each(start time.Time, before time.Time, f func(time.Time)) {
+	count := 0
+	emit := func(occurrence time.Time) bool {
+		if !occurrence.Before(before) || r.after(occurrence) || (r.count > 0 && count >= r.count) {
+			return false
+		}
+
+		count++
+		f(occurrence)
+		return true
+	}
+
+	if !emit(start) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		days := r.days(start, period)
+		if len(days) == 0 {
+			continue
+		}
+
+		for _, day := range days {
+			y, m, d := day.Date()
+			h, min, s := start.Clock()
+			occurrence := time.Date(y, m, d, h, min, s, 0, start.Location())
+			if !occurrence.After(start) {
+				continue
+			}
+
+			if !emit(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// after checks if an occurrence is after the end of the rule
+func (r *rule) after(occurrence time.Time) bool {
+	if r.until.IsZero() {
+		return false
+	}
+
+	if r.untilDate {
+		return !occurrence.Before(r.until.AddDate(0, 0, 1))
+	}
+
+	return occurrence.After(r.until)
+}
+
+// days returns the days of the occurrences of a period of the rule, as the days, weeks or
+// months since the start of the event, in order
+func (r *rule) days(start time.Time, period int) []time.Time {
+	y, m, d := start.Date()
+
+	switch r.freq {
+	case "DAILY":
+		day := time.Date(y, m, d+period*r.interval, 0, 0, 0, 0, time.UTC)
+		if len(r.byDay) > 0 && !r.hasWeekday(day.Weekday()) {
+			return nil
+		}
+
+		return []time.Time{day}
+	case "WEEKLY":
+		offset := (int(start.Weekday()) - int(r.weekStart) + 7) % 7
+		weekStart := time.Date(y, m, d-offset+7*period*r.interval, 0, 0, 0, 0, time.UTC)
+
+		days := []time.Time{}
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if (len(r.byDay) == 0 && day.Weekday() == start.Weekday()) || r.hasWeekday(day.Weekday()) {
+				days = append(days, day)
+			}
+		}
+
+		return days
+	}
+
+	first := time.Date(y, m+time.Month(period*r.interval), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	monthDays := map[int]bool{}
+	for _, monthDay := range r.byMonthDay {
+		if monthDay < 0 {
+			monthDay = last + 1 + monthDay
+		}
+		monthDays[monthDay] = true
+	}
+	for _, byDay := range r.byDay {
+		for day := 1; day <= last; day++ {
+			date := first.AddDate(0, 0, day-1)
+			if date.Weekday() != byDay.weekday {
+				continue
+			}
+
+			week := (day-1)/7 + 1
+			if byDay.week < 0 {
+				week = -((last-day)/7 + 1)
+			}
+			if byDay.week == 0 || byDay.week == week {
+				monthDays[day] = true
+			}
+		}
+	}
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		monthDays[d] = true
+	}
+
+	days := []time.Time{}
+	for monthDay := range monthDays {
+		if monthDay >= 1 && monthDay <= last {
+			days = append(days, first.AddDate(0, 0, monthDay-1))
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	return days
+}
+
+// hasWeekday checks if the weekday is one of the weekdays of the rule
+func (r *rule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.byDay {
+		if day.weekday == weekday {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,165 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(day int, month time.Month, hour int) time.Time {
+		return time.Date(2020, month, day, hour, 0, 0, 0, loc)
+	}
+	// the days of March 2020, the range of the agenda
+	from := at(1, time.March, 0)
+	until := at(1, time.April, 0)
+
+	tests := []struct {
+		name    string
+		start   time.Time
+		rrule   string
+		exdates []time.Time
+		starts  []time.Time
+	}{
+		{"not recurring", at(2, time.March, 10), "", nil, []time.Time{at(2, time.March, 10)}},
+		{"not recurring out of range", at(2, time.April, 10), "", nil, []time.Time{}},
+		{"daily with count", at(30, time.March, 10), "FREQ=DAILY;COUNT=5", nil,
+			[]time.Time{at(30, time.March, 10), at(31, time.March, 10)}},
+		// started before the range, and crossing the change to summer time on the 29th
+		{"daily with interval and exdate", at(20, time.February, 9), "FREQ=DAILY;INTERVAL=10", []time.Time{at(11, time.March, 9)},
+			[]time.Time{at(1, time.March, 9), at(21, time.March, 9), at(31, time.March, 9)}},
+		{"daily on weekdays until a date", at(26, time.March, 10), "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20200330", nil,
+			[]time.Time{at(26, time.March, 10), at(27, time.March, 10), at(30, time.March, 10)}},
+		{"weekly", at(3, time.February, 10), "FREQ=WEEKLY", nil,
+			[]time.Time{at(2, time.March, 10), at(9, time.March, 10), at(16, time.March, 10), at(23, time.March, 10), at(30, time.March, 10)}},
+		{"weekly every other week on several days until a time", at(2, time.March, 10), "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20200316T090000Z", nil,
+			[]time.Time{at(2, time.March, 10), at(5, time.March, 10), at(16, time.March, 10)}},
+		{"monthly on the day", at(31, time.January, 12), "FREQ=MONTHLY", nil, []time.Time{at(31, time.March, 12)}},
+		{"monthly on the last Friday", at(31, time.January, 12), "FREQ=MONTHLY;BYDAY=-1FR", nil, []time.Time{at(27, time.March, 12)}},
+		{"monthly on the first and fifteenth", at(1, time.February, 8), "FREQ=MONTHLY;BYMONTHDAY=1,15;COUNT=3", nil,
+			[]time.Time{at(1, time.March, 8)}},
+		{"ended before the range", at(3, time.February, 10), "FREQ=WEEKLY;COUNT=4", nil, []time.Time{}},
+	}
+
+	for _, test := range tests {
+		event := Event{UID: test.name, Start: test.start, End: test.start.Add(90 * time.Minute), RRule: test.rrule, ExDates: test.exdates}
+		occurrences, err := event.Occurrences(from, until)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+
+		if len(occurrences) != len(test.starts) {
+			t.Errorf("%s: unexpected occurrences %v", test.name, starts(occurrences))
+			continue
+		}
+		for i, occurrence := range occurrences {
+			if !occurrence.Start.Equal(test.starts[i]) || !occurrence.End.Equal(test.starts[i].Add(90*time.Minute)) {
+				t.Errorf("%s: unexpected occurrence %v - %v, want %v", test.name, occurrence.Start, occurrence.End, test.starts[i])
+			}
+		}
+	}
+}
+
+func TestOccurrencesAllDay(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 3, 28, 0, 0, 0, 0, loc)
+	event := Event{Start: start, End: start.AddDate(0, 0, 1), AllDay: true, RRule: "FREQ=DAILY;COUNT=3"}
+	occurrences, err := event.Occurrences(start, start.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(occurrences) != 3 {
+		t.Fatalf("unexpected occurrences %v", starts(occurrences))
+	}
+	// the change to summer time on the 29th does not move the days
+	last := occurrences[2]
+	if !last.Start.Equal(time.Date(2020, 3, 30, 0, 0, 0, 0, loc)) || !last.End.Equal(time.Date(2020, 3, 31, 0, 0, 0, 0, loc)) {
+		t.Errorf("unexpected last occurrence %v - %v", last.Start, last.End)
+	}
+}
+
+func TestOccurrencesUnsupported(t *testing.T) {
+	start := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	for _, rrule := range []string{
+		"FREQ=YEARLY",
+		"FREQ=HOURLY;COUNT=3",
+		"FREQ=WEEKLY;BYSETPOS=1",
+		"FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;UNTIL=2020-03-31",
+		"RRULE:FREQ=DAILY",
+	} {
+		event := Event{Start: start, RRule: rrule}
+		if occurrences, err := event.Occurrences(start, start.AddDate(0, 1, 0)); err == nil {
+			t.Errorf("%s: expected an error, got %v", rrule, starts(occurrences))
+		}
+	}
+}
+
+func TestParseRecurrences(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:weekly\r\n" +
+		"DTSTART;TZID=Europe/Madrid:20200302T100000\r\n" +
+		"RRULE:FREQ=WEEKLY;COUNT=4\r\n" +
+		"EXDATE;TZID=Europe/Madrid:20200309T100000,20200316T100000\r\n" +
+		"EXDATE:20200323T090000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:weekly\r\n" +
+		"RECURRENCE-ID;TZID=Europe/Madrid:20200316T100000\r\n" +
+		"DTSTART;TZID=Europe/Madrid:20200317T120000\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse(strings.NewReader(feed), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	if len(events[0].ExDates) != 3 || !events[0].ExDates[2].Equal(time.Date(2020, 3, 23, 10, 0, 0, 0, loc)) {
+		t.Errorf("unexpected excluded dates %v", events[0].ExDates)
+	}
+	if !events[1].RecurrenceID.Equal(time.Date(2020, 3, 16, 10, 0, 0, 0, loc)) {
+		t.Errorf("unexpected recurrence ID %v", events[1].RecurrenceID)
+	}
+
+	occurrences, err := events[0].Occurrences(time.Date(2020, 3, 1, 0, 0, 0, 0, loc), time.Date(2020, 4, 1, 0, 0, 0, 0, loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 1 || !occurrences[0].Start.Equal(time.Date(2020, 3, 2, 10, 0, 0, 0, loc)) {
+		t.Errorf("unexpected occurrences %v", starts(occurrences))
+	}
+}
+
+// starts returns the starts of the events
+func starts(events []Event) []time.Time {
+	times := []time.Time{}
+	for _, event := range events {
+		times = append(times, event.Start)
+	}
+
+	return times
+}
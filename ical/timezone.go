@@ -0,0 +1,208 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zone represents a VTIMEZONE of a calendar, as the ones of Outlook named with the Windows
+// time zones, as "Romance Standard Time", with the observances of its standard and daylight
+// saving times
+type zone struct {
+	id          string
+	location    *time.Location
+	observances []observance
+}
+
+// observance represents a STANDARD or DAYLIGHT block of a VTIMEZONE: the offset from UTC
+// since its onset, and its yearly rule, if any, as "FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10"
+type observance struct {
+	offset int
+	start  time.Time
+	yearly bool
+	month  time.Month
+	// week is the ordinal of the weekday in the month, negative from its end, or zero for
+	// the day of the month
+	week     int
+	weekday  time.Weekday
+	monthDay int
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// timezones reads the VTIMEZONE blocks of a calendar, by TZID. The blocks with the
+// X-LIC-LOCATION property of a known time zone use it
+func timezones(lines []string) map[string]*zone {
+	zones := map[string]*zone{}
+
+	var current *zone
+	var obs *observance
+	for _, line := range lines {
+		name, _, value := property(line)
+
+		switch {
+		case name == "BEGIN" && value == "VTIMEZONE":
+			current = &zone{}
+		case current == nil:
+			continue
+		case name == "END" && value == "VTIMEZONE":
+			if current.id != "" && (current.location != nil || len(current.observances) > 0) {
+				zones[current.id] = current
+			}
+			current = nil
+		case name == "TZID":
+			current.id = value
+		case name == "X-LIC-LOCATION":
+			if loc, err := time.LoadLocation(value); err == nil {
+				current.location = loc
+			}
+		case name == "BEGIN" && (value == "STANDARD" || value == "DAYLIGHT"):
+			obs = &observance{}
+		case obs == nil:
+			continue
+		case name == "END" && (value == "STANDARD" || value == "DAYLIGHT"):
+			current.observances = append(current.observances, *obs)
+			obs = nil
+		case name == "TZOFFSETTO":
+			obs.offset = parseOffset(value)
+		case name == "DTSTART":
+			obs.start, _ = time.Parse("20060102T150405", value)
+		case name == "RRULE":
+			obs.setRule(value)
+		}
+	}
+
+	return zones
+}
+
+// parseOffset parses an offset from UTC, as "+0100" or "-053000", in seconds
+func parseOffset(value string) int {
+	if len(value) < 5 {
+		return 0
+	}
+
+	hours, _ := strconv.Atoi(value[1:3])
+	minutes, _ := strconv.Atoi(value[3:5])
+	seconds := 0
+	if len(value) >= 7 {
+		seconds, _ = strconv.Atoi(value[5:7])
+	}
+
+	offset := hours*3600 + minutes*60 + seconds
+	if value[0] == '-' {
+		return -offset
+	}
+
+	return offset
+}
+
+// setRule sets the yearly rule of the observance. Other rules are not supported, so that
+// the observance starts only on its DTSTART
+func (o *observance) setRule(rule string) {
+	parts := map[string]string{}
+	for _, part := range strings.Split(rule, ";") {
+		if eq := strings.Index(part, "="); eq >= 0 {
+			parts[strings.ToUpper(part[:eq])] = strings.ToUpper(part[eq+1:])
+		}
+	}
+
+	month, err := strconv.Atoi(parts["BYMONTH"])
+	if parts["FREQ"] != "YEARLY" || err != nil || month < 1 || month > 12 {
+		return
+	}
+	o.month = time.Month(month)
+
+	if day := parts["BYDAY"]; len(day) >= 2 {
+		weekday, ok := weekdays[day[len(day)-2:]]
+		if !ok {
+			return
+		}
+
+		week := 1
+		if ordinal := day[:len(day)-2]; ordinal != "" {
+			week, err = strconv.Atoi(ordinal)
+			if err != nil || week == 0 {
+				return
+			}
+		}
+
+		o.week = week
+		o.weekday = weekday
+	} else {
+		o.monthDay, err = strconv.Atoi(parts["BYMONTHDAY"])
+		if err != nil || o.monthDay < 1 {
+			return
+		}
+	}
+
+	o.yearly = true
+}
+
+// onsets returns the wall times the observance starts until the year of a wall time
+func (o *observance) onsets(wall time.Time) []time.Time {
+	if !o.yearly {
+		return []time.Time{o.start}
+	}
+
+	onsets := []time.Time{}
+	for _, year := range []int{wall.Year() - 1, wall.Year()} {
+		if onset, ok := o.onset(year); ok {
+			onsets = append(onsets, onset)
+		}
+	}
+
+	return onsets
+}
+
+// onset returns the wall time the yearly observance starts in a year, if it starts that year
+func (o *observance) onset(year int) (time.Time, bool) {
+	if year < o.start.Year() {
+		return time.Time{}, false
+	}
+
+	h, m, s := o.start.Clock()
+	if o.week == 0 {
+		return time.Date(year, o.month, o.monthDay, h, m, s, 0, time.UTC), true
+	}
+
+	var day time.Time
+	if o.week > 0 {
+		day = time.Date(year, o.month, 1, h, m, s, 0, time.UTC)
+		day = day.AddDate(0, 0, (int(o.weekday)-int(day.Weekday())+7)%7+(o.week-1)*7)
+	} else {
+		day = time.Date(year, o.month+1, 0, h, m, s, 0, time.UTC)
+		day = day.AddDate(0, 0, -((int(day.Weekday())-int(o.weekday)+7)%7)+(o.week+1)*7)
+	}
+	if day.Month() != o.month {
+		return time.Time{}, false
+	}
+
+	return day, true
+}
+
+// in returns the time of a wall time of the zone, with the offset of the observance with
+// the latest onset before it
+func (z *zone) in(wall time.Time) time.Time {
+	y, m, d := wall.Date()
+	h, min, s := wall.Clock()
+	if z.location != nil {
+		return time.Date(y, m, d, h, min, s, 0, z.location)
+	}
+
+	offset := z.observances[0].offset
+	var latest time.Time
+	for _, o := range z.observances {
+		for _, onset := range o.onsets(wall) {
+			if !onset.After(wall) && onset.After(latest) {
+				latest = onset
+				offset = o.offset
+			}
+		}
+	}
+
+	return time.Date(y, m, d, h, min, s, 0, time.FixedZone(z.id, offset))
+}
@@ -3,9 +3,11 @@ package indexers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	es "github.com/elastic/go-elasticsearch/v7"
 	esapi "github.com/elastic/go-elasticsearch/v7/esapi"
@@ -216,3 +218,178 @@ func getElasticsearchClient() (*es.Client, error) {
 
 	return esInstance, nil
 }
+
+type searchHit struct {
+	Source models.AgendaEvent `json:"_source"`
+}
+
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []searchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// Search retrieves all stored events matching the query, sorted by date
+func (ei *ElasticsearchIndexer) Search(ctx context.Context, query EventsQuery) ([]models.AgendaEvent, error) {
+	esClient, err := getElasticsearchClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the APM transaction
+	txn := apm.DefaultTracer.StartTransaction("Search()", "search")
+	// Add current user to the transaction metadata
+	txn.Context.SetUsername("cansino")
+	// Store the transaction in a context
+	txCtx := apm.ContextWithTransaction(ctx, txn)
+	// Mark the transaction as completed
+	defer txn.End()
+
+	body, err := getSearchBody(query)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := esClient.Search(
+		esClient.Search.WithContext(txCtx),
+		esClient.Search.WithIndex("cansino"),
+		esClient.Search.WithBody(strings.NewReader(body)),
+		esClient.Search.WithScroll(time.Minute),
+		esClient.Search.WithSize(500),
+	)
+	if err != nil {
+		apm.CaptureError(txCtx, err).Send()
+		log.WithFields(log.Fields{
+			"index": "cansino",
+			"body":  body,
+			"error": err,
+		}).Error("Error getting search response")
+		return nil, err
+	}
+
+	events := []models.AgendaEvent{}
+	for {
+		page, err := decodeSearchResponse(res)
+		if err != nil {
+			apm.CaptureError(txCtx, err).Send()
+			return nil, err
+		}
+
+		for _, hit := range page.Hits.Hits {
+			events = append(events, hit.Source)
+		}
+
+		if len(page.Hits.Hits) == 0 || page.ScrollID == "" {
+			if page.ScrollID != "" {
+				clearRes, err := esClient.ClearScroll(
+					esClient.ClearScroll.WithContext(txCtx),
+					esClient.ClearScroll.WithScrollID(page.ScrollID),
+				)
+				if err == nil {
+					clearRes.Body.Close()
+				}
+			}
+			break
+		}
+
+		res, err = esClient.Scroll(
+			esClient.Scroll.WithContext(txCtx),
+			esClient.Scroll.WithScrollID(page.ScrollID),
+			esClient.Scroll.WithScroll(time.Minute),
+		)
+		if err != nil {
+			apm.CaptureError(txCtx, err).Send()
+			log.WithFields(log.Fields{
+				"index": "cansino",
+				"error": err,
+			}).Error("Error getting scroll response")
+			return nil, err
+		}
+	}
+
+	txn.Result = "success"
+
+	log.WithFields(log.Fields{
+		"owner":  query.Owner,
+		"region": query.Region,
+		"since":  query.Since,
+		"until":  query.Until,
+		"events": len(events),
+	}).Debug("Events retrieved")
+
+	return events, nil
+}
+
+func decodeSearchResponse(res *esapi.Response) (searchResponse, error) {
+	defer res.Body.Close()
+
+	page := searchResponse{}
+	if res.IsError() {
+		log.WithFields(log.Fields{
+			"status": res.Status(),
+		}).Error("Error searching documents")
+		return page, fmt.Errorf("error searching documents: %s", res.Status())
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("Error parsing the search response body")
+		return page, err
+	}
+
+	return page, nil
+}
+
+// getSearchBody builds the search request for the query, sorting by date
+func getSearchBody(query EventsQuery) (string, error) {
+	filters := []map[string]interface{}{}
+	if query.Owner != "" {
+		// the owner may be the canonical role, the raw owner or the person
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []map[string]interface{}{
+					{"term": map[string]interface{}{"owner": query.Owner}},
+					{"term": map[string]interface{}{"originalOwner": query.Owner}},
+					{"term": map[string]interface{}{"personId": query.Owner}},
+				},
+				"minimum_should_match": 1,
+			},
+		})
+	}
+	if query.Region != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"region": query.Region},
+		})
+	}
+
+	dateRange := map[string]interface{}{}
+	if !query.Since.IsZero() {
+		dateRange["gte"] = query.Since.Format(time.RFC3339)
+	}
+	if !query.Until.IsZero() {
+		dateRange["lte"] = query.Until.Format(time.RFC3339)
+	}
+	if len(dateRange) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"date": dateRange},
+		})
+	}
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"filter": filters},
+		},
+		"sort": []map[string]interface{}{
+			{"date": map[string]interface{}{"order": "asc"}},
+		},
+	}
+
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
@@ -3,6 +3,7 @@ package indexers
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/mdelapenya/cansino/models"
 )
@@ -20,3 +21,26 @@ func GetIndexer(name string) (Indexer, error) {
 
 	return nil, errors.New("indexer " + name + " not found")
 }
+
+// EventsQuery represents the criteria to retrieve stored events. Empty fields are not
+// used as criteria
+type EventsQuery struct {
+	Owner  string
+	Region string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Searcher methods required to retrieve stored events
+type Searcher interface {
+	Search(context.Context, EventsQuery) ([]models.AgendaEvent, error)
+}
+
+// GetSearcher returns the searcher by name
+func GetSearcher(name string) (Searcher, error) {
+	if name == "elasticsearch" {
+		return &ElasticsearchIndexer{}, nil
+	}
+
+	return nil, errors.New("searcher " + name + " not found")
+}
@@ -0,0 +1,228 @@
+package lobbies
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/entities"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Group represents an interest group in a register of lobbies
+type Group struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Category string   `json:"category"`
+	URL      string   `json:"url"`
+}
+
+// columns of the CSV registers, including the Spanish names used by transparency portals
+var csvColumns = map[string][]string{
+	"id":       {"id", "identificador", "numero", "numero de inscripcion", "inscripcion"},
+	"name":     {"name", "nombre", "denominacion", "razon social", "nombre o razon social"},
+	"aliases":  {"aliases", "alias", "siglas", "otras denominaciones"},
+	"category": {"category", "categoria", "tipo", "tipo de grupo"},
+	"url":      {"url", "enlace", "web"},
+}
+
+// Load reads a register of interest groups from a CSV or JSON file, based on its extension
+func Load(path string) (*Register, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := []Group{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		groups, err = ParseCSV(file)
+	case ".json":
+		var bytes []byte
+		bytes, err = ioutil.ReadAll(file)
+		if err == nil {
+			err = json.Unmarshal(bytes, &groups)
+		}
+	default:
+		err = fmt.Errorf("unsupported register file %s. Please use CSV or JSON", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegister(groups), nil
+}
+
+// ParseCSV reads the interest groups from CSV with a header row including the name of the
+// groups. Aliases are separated by '|'
+func ParseCSV(r io.Reader) ([]Group, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []Group{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, header := range records[0] {
+		normalized := analysis.Normalize(header)
+		for field, names := range csvColumns {
+			for _, name := range names {
+				if normalized == name {
+					if _, exists := columns[field]; !exists {
+						columns[field] = i
+					}
+				}
+			}
+		}
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("the CSV header does not include the name of the groups: %v", records[0])
+	}
+
+	value := func(record []string, field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	groups := []Group{}
+	for i, record := range records[1:] {
+		group := Group{
+			ID:       value(record, "id"),
+			Name:     value(record, "name"),
+			Aliases:  []string{},
+			Category: value(record, "category"),
+			URL:      value(record, "url"),
+		}
+		if group.Name == "" {
+			continue
+		}
+		if group.ID == "" {
+			group.ID = fmt.Sprintf("%d", i+1)
+		}
+
+		for _, alias := range strings.Split(value(record, "aliases"), "|") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				group.Aliases = append(group.Aliases, alias)
+			}
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// Register represents a register of interest groups
+type Register struct {
+	groups []Group
+}
+
+// NewRegister returns the register of the interest groups
+func NewRegister(groups []Group) *Register {
+	return &Register{groups: groups}
+}
+
+// Match returns the registered group with the most similar name or alias to the name of
+// an organization, if their similarity is at least the threshold. An acronym matches the
+// groups including it as a word in their names
+func (r *Register) Match(organization string, threshold float64) (Group, bool) {
+	best := Group{}
+	bestScore := threshold
+	found := false
+	for _, group := range r.groups {
+		for _, name := range append([]string{group.Name}, group.Aliases...) {
+			score := analysis.OrganizationSimilarity(organization, name)
+			if score >= bestScore {
+				best = group
+				bestScore = score
+				found = true
+			}
+		}
+	}
+
+	return best, found
+}
+
+// LinkEvent sets the registered interest groups of the organizations of an event
+func (r *Register) LinkEvent(event *models.AgendaEvent, threshold float64) {
+	event.Lobby = []models.Lobby{}
+	for _, organization := range event.Organizations {
+		group, ok := r.Match(organization.Name, threshold)
+		if !ok {
+			continue
+		}
+
+		event.Lobby = append(event.Lobby, models.Lobby{
+			ID:           group.ID,
+			Name:         group.Name,
+			Organization: organization.Name,
+		})
+	}
+}
+
+// Meeting represents an event in which an organization took part
+type Meeting struct {
+	Date         time.Time `json:"date"`
+	EventID      string    `json:"eventId"`
+	Owner        string    `json:"owner"`
+	Region       string    `json:"region"`
+	Organization string    `json:"organization"`
+	Type         string    `json:"type"`
+	Description  string    `json:"description"`
+	Registered   bool      `json:"registered"`
+	Lobby        *Group    `json:"lobby,omitempty"`
+}
+
+// Meetings returns the meetings of the owners with organizations, checking if they are in
+// the register. Public bodies are not considered, as they are not interest groups
+func (r *Register) Meetings(events []models.AgendaEvent, threshold float64) []Meeting {
+	meetings := []Meeting{}
+	for _, event := range events {
+		for _, organization := range event.Organizations {
+			if organization.Type == entities.Public {
+				continue
+			}
+
+			meeting := Meeting{
+				Date:         event.Date,
+				EventID:      event.ID,
+				Owner:        event.Owner,
+				Region:       event.Region,
+				Organization: organization.Name,
+				Type:         organization.Type,
+				Description:  event.OriginalDescription,
+			}
+
+			if group, ok := r.Match(organization.Name, threshold); ok {
+				meeting.Registered = true
+				meeting.Lobby = &group
+			}
+
+			meetings = append(meetings, meeting)
+		}
+	}
+
+	sort.SliceStable(meetings, func(i, j int) bool {
+		return meetings[i].Date.Before(meetings[j].Date)
+	})
+
+	return meetings
+}
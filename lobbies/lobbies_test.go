@@ -0,0 +1,57 @@
+package lobbies
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	register := NewRegister([]Group{
+		{ID: "1", Name: "Fundación Telefónica"},
+		{ID: "2", Name: "Confederación Española de Organizaciones Empresariales", Aliases: []string{"CEOE"}},
+		{ID: "3", Name: "Asociación Agraria Jóvenes Agricultores", Aliases: []string{"ASAJA"}},
+		{ID: "4", Name: "Iberdrola, S.A."},
+	})
+
+	tests := []struct {
+		organization string
+		id           string
+	}{
+		{"Fundación Telefónica", "1"},
+		{"Telefónica, Fundación", "1"},
+		{"CEOE", "2"},
+		{"ASAJA", "3"},
+		{"Iberdrola", "4"},
+		{"Fundación Iberdrola", ""},
+		{"Iberdrola Renovables", ""},
+		{"Asociación de Vecinos", ""},
+	}
+
+	for _, test := range tests {
+		group, ok := register.Match(test.organization, 0.9)
+		if test.id == "" {
+			if ok {
+				t.Errorf("%q matched the group %s", test.organization, group.ID)
+			}
+			continue
+		}
+
+		if !ok || group.ID != test.id {
+			t.Errorf("%q matched %v %s, want %s", test.organization, ok, group.ID, test.id)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	csv := "Número de inscripción,Nombre o razón social,Siglas,Categoría\n" +
+		"12,Asociación Agraria Jóvenes Agricultores,ASAJA,Organizaciones profesionales\n"
+
+	groups, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 || groups[0].ID != "12" || groups[0].Aliases[0] != "ASAJA" {
+		t.Errorf("unexpected groups %+v", groups)
+	}
+}
@@ -2,60 +2,124 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/holidays"
 	log "github.com/sirupsen/logrus"
 	"go.elastic.co/apm/module/apmhttp"
 )
 
+// NewCollector returns a collector visiting only the allowed domains, and the links on the
+// scraped pages no further, with a delay between the requests to the same domain, if any,
+// and caching the responses if cached. Its requests skip the verification of the TLS
+// certificates, and are instrumented with APM Agent Go. File URLs are read only with files
+// set, so that no other collector reads the local files
+func NewCollector(allowedDomains []string, delay time.Duration, cached bool, files bool) *colly.Collector {
+	options := []colly.CollectorOption{
+		colly.AllowedDomains(allowedDomains...),
+
+		// MaxDepth is 1, so only the links on the scraped page
+		// is visited, and no further links are followed
+		colly.MaxDepth(1),
+	}
+	if cached {
+		// Cache responses to prevent multiple download of pages
+		// even if the collector is restarted
+		options = append(options, colly.CacheDir("./.cansino_cache"))
+	}
+
+	// Instantiate default collector
+	c := colly.NewCollector(options...)
+
+	if delay > 0 {
+		c.Limit(&colly.LimitRule{
+			DomainGlob:  "*",
+			Delay:       delay,
+			Parallelism: 1,
+		})
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	if files {
+		// agendas read from local files, as the feeds downloaded by hand
+		transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	}
+
+	skipTlsClient := &http.Client{
+		Transport: transport,
+	}
+
+	// instrument Colly's HTTP requests with APM Agent Go
+	apmHTTPClient := apmhttp.WrapClient(skipTlsClient)
+	c.SetClient(apmHTTPClient)
+
+	return c
+}
+
+// Version of cansino, recorded in the scraped events. Set it at build time with
+// -ldflags "-X github.com/mdelapenya/cansino/models.Version=<version>"
+var Version = "dev"
+
 // Agenda represents an agenda for a day
 type Agenda struct {
 	AllowedDomains []string   `json:"-"`
 	Date           time.Time  `json:"date"`
 	Day            AgendaDate `json:"day"`
+	// Delay between the requests to the same domain, to be polite with the sources
+	Delay time.Duration `json:"-"`
+	// DetailProcessor merges the detail page of an event into the event, if the region
+	// links its events to detail pages
+	DetailProcessor func(event *AgendaEvent, e *colly.HTMLElement) `json:"-"`
+	DetailSelector  string                                         `json:"-"`
 	// DoPost: if the public agenda requires POST http method
-	DoPost        bool                                  `json:"-"`
-	Events        []AgendaEvent                         `json:"events"`
+	DoPost bool          `json:"-"`
+	Events []AgendaEvent `json:"events"`
+	// FollowDetails: if the detail pages of the events are visited
+	FollowDetails bool                                  `json:"-"`
+	Holiday       string                                `json:"holiday"`
 	HTMLSelector  string                                `json:"-"`
 	HTMLProcessor func(a *Agenda, e *colly.HTMLElement) `json:"-"`
-	// JSONProcessor only for processing POST requests
+	// JSONProcessor processes the bodies of the POST requests, and of the feeds and APIs
+	// requested with GET, instead of their HTML
 	JSONProcessor func(a *Agenda, body []byte) `json:"-"`
 	ID            string                       `json:"id"`
-	Owner         string                       `json:"owner"`
-	Region        string                       `json:"-"`
-	Payload       string                       `json:"-"`
-	URL           string                       `json:"url"`
-	URLFormat     string                       `json:"-"`
+	// LocalFile: if the agenda is read from a file URL, as the feeds downloaded by hand
+	LocalFile bool `json:"-"`
+	// MaxPages guards the pagination, defaulting to DefaultMaxPages
+	MaxPages int `json:"-"`
+	// NextPageSelector selects the link to the next page of HTML agendas
+	NextPageSelector string `json:"-"`
+	Owner            string `json:"owner"`
+	// PageParam is the zero-based page parameter of the URL, or of the payload of POST
+	// agendas, requested until a page has no new events
+	PageParam string `json:"-"`
+	Region    string `json:"-"`
+	Payload   string `json:"-"`
+	// Uncached: if the responses are not cached, as the ones of the feeds, whose URL is the
+	// same for every day
+	Uncached bool `json:"-"`
+	// Until is the last day of the agendas fetching several days, zero for a single day
+	Until     time.Time `json:"-"`
+	URL       string    `json:"url"`
+	URLFormat string    `json:"-"`
+	// URLVariant identifies the URL used among the ones of the region, as its historical
+	// and current agendas
+	URLVariant string `json:"urlVariant"`
 }
 
 // Scrap scrappes an agenda
 func (a *Agenda) Scrap(ctx context.Context) error {
-	// Instantiate default collector
-	c := colly.NewCollector(
-		colly.AllowedDomains(a.AllowedDomains...),
-
-		// Cache responses to prevent multiple download of pages
-		// even if the collector is restarted
-		colly.CacheDir("./.cansino_cache"),
-		// MaxDepth is 1, so only the links on the scraped page
-		// is visited, and no further links are followed
-		colly.MaxDepth(1),
-	)
-
-	skipTlsClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
-
-	// instrument Colly's HTTP requests with APM Agent Go
-	apmHTTPClient := apmhttp.WrapClient(skipTlsClient)
-	c.SetClient(apmHTTPClient)
+	c := NewCollector(a.AllowedDomains, a.Delay, !a.Uncached, a.LocalFile)
 
 	// Before making a request print "Visiting ..."
 	c.OnRequest(func(r *colly.Request) {
@@ -79,26 +143,76 @@ func (a *Agenda) Scrap(ctx context.Context) error {
 		}).Error("Failed to parse HTML")
 	})
 
-	var err error
-	if a.DoPost {
+	if a.JSONProcessor != nil {
 		c.OnResponse(func(r *colly.Response) {
+			from := len(a.Events)
 			a.JSONProcessor(a, r.Body)
+			a.setSource(from, r)
 		})
-
-		err = c.PostRaw(a.URL, []byte(a.Payload))
 	} else {
 		c.OnHTML(a.HTMLSelector, a.htmlProcess)
-
-		err = c.Visit(a.URL)
 	}
+	a.paginate(c)
+
+	err := a.visit(c, a.URL, a.Payload)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"url":   a.URL,
 			"error": err,
 		}).Error("Error visiting URL")
+		return err
+	}
+
+	if a.FollowDetails && a.DetailProcessor != nil {
+		a.scrapDetails(c)
 	}
 
-	return err
+	return nil
+}
+
+// scrapDetails visits the detail pages of the events, with the same allowed domains and
+// limits of the collector of the agenda, merging them into the events
+func (a *Agenda) scrapDetails(c *colly.Collector) {
+	details := c.Clone()
+
+	details.OnRequest(func(r *colly.Request) {
+		log.WithFields(log.Fields{
+			"url": r.URL.String(),
+		}).Debug("Visiting detail url")
+	})
+
+	details.OnError(func(r *colly.Response, err error) {
+		log.WithFields(log.Fields{
+			"url":   r.Request.URL,
+			"error": err,
+		}).Warn("Failed to visit the detail page")
+	})
+
+	details.OnHTML(a.DetailSelector, func(e *colly.HTMLElement) {
+		i, ok := e.Request.Ctx.GetAny("event").(int)
+		if !ok {
+			return
+		}
+
+		a.DetailProcessor(&a.Events[i], e)
+	})
+
+	for i, event := range a.Events {
+		if event.DetailURL == "" {
+			continue
+		}
+
+		ctx := colly.NewContext()
+		ctx.Put("event", i)
+		err := details.Request("GET", event.DetailURL, nil, ctx, nil)
+		if err != nil {
+			// pages out of the allowed domains, or already visited
+			log.WithFields(log.Fields{
+				"url":   event.DetailURL,
+				"error": err,
+			}).Debug("Skipping detail url")
+		}
+	}
 }
 
 // ToJSON exports the agenda to JSON
@@ -107,7 +221,81 @@ func (a *Agenda) ToJSON() ([]byte, error) {
 }
 
 func (a *Agenda) htmlProcess(e *colly.HTMLElement) {
+	from := len(a.Events)
 	a.HTMLProcessor(a, e)
+	a.setSource(from, e.Response)
+}
+
+// setSource records the source of the events processed from a response, from the index of
+// the first one: the URL and payload of the request, the time, the hash of the response
+// content, the version of cansino and the variant of the URL
+func (a *Agenda) setSource(from int, r *colly.Response) {
+	if from >= len(a.Events) {
+		return
+	}
+
+	sum := sha256.Sum256(r.Body)
+	hash := hex.EncodeToString(sum[:])
+	scrapedAt := time.Now()
+
+	payload := r.Ctx.Get("payload")
+
+	for i := from; i < len(a.Events); i++ {
+		a.Events[i].SourceURL = r.Request.URL.String()
+		a.Events[i].SourcePayload = payload
+		a.Events[i].ScrapedAt = &scrapedAt
+		a.Events[i].ContentHash = hash
+		a.Events[i].Version = Version
+		a.Events[i].URLVariant = a.URLVariant
+	}
+}
+
+// SplitDays splits an agenda of several days into the agendas of the days with events,
+// with the IDs of the agendas of those days. Events out of the days of the agenda are
+// discarded, as they belong to the agendas of other days
+func (a *Agenda) SplitDays() []*Agenda {
+	if a.Until.IsZero() {
+		return []*Agenda{a}
+	}
+
+	// the IDs of the agendas end with their local dates
+	prefix := strings.TrimSuffix(a.ID, a.Date.Local().Format("2006-01-02"))
+
+	end := a.Until.AddDate(0, 0, 1)
+
+	agendas := []*Agenda{}
+	days := map[string]*Agenda{}
+	for _, event := range a.Events {
+		if event.Date.Before(a.Date) || !event.Date.Before(end) {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"eventID":  event.ID,
+				"date":     event.Date,
+			}).Warn("Event out of the days of the agenda. Discarding it")
+			continue
+		}
+
+		key := event.Date.Format("2006-01-02")
+		day, ok := days[key]
+		if !ok {
+			y, m, d := event.Date.Date()
+
+			day = &Agenda{}
+			*day = *a
+			day.Date = time.Date(y, m, d, 0, 0, 0, 0, a.Date.Location())
+			day.Day = AgendaDate{Day: d, Month: int(m), Year: y}
+			day.Events = []AgendaEvent{}
+			day.ID = prefix + day.Date.Local().Format("2006-01-02")
+			day.Until = time.Time{}
+
+			days[key] = day
+			agendas = append(agendas, day)
+		}
+
+		day.Events = append(day.Events, event)
+	}
+
+	return agendas
 }
 
 // AgendaDate represents a day
@@ -117,22 +305,64 @@ type AgendaDate struct {
 	Year  int `json:"year"`
 }
 
-//ToDate converts a date into time.Time
+// ToDate converts a date into time.Time
 func (ad *AgendaDate) ToDate() time.Time {
 	return time.Date(ad.Year, time.Month(ad.Month), ad.Day, 0, 0, 0, 0, time.UTC)
 }
 
 // AgendaEvent represents an event in the agenda
 type AgendaEvent struct {
-	Date                time.Time  `json:"date"`
-	Description         string     `json:"description"`
-	OriginalDescription string     `json:"originalDescription"`
-	ID                  string     `json:"id"`
-	Location            string     `json:"location"`
-	OriginalLocation    string     `json:"originalLocation"`
-	Attendance          []Attendee `json:"attendance"`
-	Owner               string     `json:"owner"`
-	Region              string     `json:"region"`
+	Coordinates         *GeoPoint      `json:"coordinates,omitempty"`
+	Date                time.Time      `json:"date"`
+	Description         string         `json:"description"`
+	DetailURL           string         `json:"detailURL"`
+	FullDescription     string         `json:"fullDescription"`
+	Photos              []string       `json:"photos"`
+	Attachments         []string       `json:"attachments"`
+	PressReleaseURL     string         `json:"pressReleaseURL"`
+	OriginalDescription string         `json:"originalDescription"`
+	EndDate             *time.Time     `json:"endDate,omitempty"`
+	AllDay              bool           `json:"allDay"`
+	Holiday             string         `json:"holiday"`
+	ID                  string         `json:"id"`
+	Location            string         `json:"location"`
+	OriginalLocation    string         `json:"originalLocation"`
+	Attendance          []Attendee     `json:"attendance"`
+	ContentHash         string         `json:"contentHash"`
+	INECode             string         `json:"ineCode"`
+	Lobby               []Lobby        `json:"lobby"`
+	Municipality        string         `json:"municipality"`
+	Organizations       []Organization `json:"organizations"`
+	Owner               string         `json:"owner"`
+	OriginalOwner       string         `json:"originalOwner"`
+	Party               string         `json:"party"`
+	PersonID            string         `json:"personId"`
+	Province            string         `json:"province"`
+	Region              string         `json:"region"`
+	ScrapedAt           *time.Time     `json:"scrapedAt,omitempty"`
+	SourcePayload       string         `json:"sourcePayload"`
+	SourceURL           string         `json:"sourceURL"`
+	TimePrecision       string         `json:"timePrecision"`
+	Topics              []string       `json:"topics"`
+	URLVariant          string         `json:"urlVariant"`
+	Version             string         `json:"version"`
+}
+
+// Precisions of the time of the events: the date of all-day events is the midnight of the
+// day, and the one of the events in a part of the day, as "por la mañana", its beginning
+const (
+	MinutePrecision    = "minute"
+	PartOfDayPrecision = "partOfDay"
+	DayPrecision       = "day"
+)
+
+// Duration returns the duration of the event, or zero if the end date is not known
+func (ae *AgendaEvent) Duration() time.Duration {
+	if ae.EndDate == nil {
+		return 0
+	}
+
+	return ae.EndDate.Sub(ae.Date)
 }
 
 // ToJSON exports the event to JSON
@@ -140,17 +370,61 @@ func (ae *AgendaEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(ae)
 }
 
+// GeoPoint represents the coordinates of the location of an event
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
 // Attendee represents a person attending an event
 type Attendee struct {
+	ID       string `json:"id"`
 	Job      string `json:"job"`
 	FullName string `json:"fullName"`
 }
 
+// Organization represents an organization mentioned in an event
+type Organization struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Type of organization: company, union, association, public or other
+	Type string `json:"type"`
+}
+
+// Lobby represents a registered interest group an organization of an event belongs to
+type Lobby struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+}
+
+// Granularities of the requests of the agendas: a request per day, or per week or month
+// for the sources publishing ranges of days
+const (
+	DayGranularity   = "day"
+	WeekGranularity  = "week"
+	MonthGranularity = "month"
+)
+
 // Region represents a region
 type Region struct {
-	Name      string
-	DoPost    bool
-	StartDate AgendaDate // when the agenda started to share agendas publicly
+	Name        string
+	DoPost      bool
+	Granularity string             // days fetched per request, a day if empty
+	StartDate   AgendaDate         // when the agenda started to share agendas publicly
+	Holidays    *holidays.Calendar // public holidays of the region
+	Owners      []Owner            // officials with public agendas, the president first
+	// DiscoverOwners returns the officials listed by the source, if it lists them, with a
+	// delay between the requests
+	DiscoverOwners func(delay time.Duration) ([]Owner, error)
+}
+
+// Owner represents an official with a public agenda, identified by the ID of the agenda in
+// the source. Sources publishing the agendas of the whole government have a single owner,
+// and the events keep the official they belong to
+type Owner struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 func (r *Region) String() string {
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitDays(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	day := func(d int, hour int) time.Time {
+		return time.Date(2020, 3, d, hour, 0, 0, 0, loc)
+	}
+
+	a := &Agenda{
+		ID:    "test-" + day(2, 0).Local().Format("2006-01-02"),
+		Date:  day(2, 0),
+		Until: day(4, 0),
+		Events: []AgendaEvent{
+			{ID: "before", Date: day(1, 10)},
+			{ID: "first", Date: day(2, 10)},
+			{ID: "last", Date: day(4, 23)},
+			{ID: "second", Date: day(2, 12)},
+			{ID: "after", Date: day(5, 0)},
+		},
+	}
+
+	agendas := a.SplitDays()
+	if len(agendas) != 2 {
+		t.Fatalf("unexpected agendas %+v", agendas)
+	}
+
+	if len(agendas[0].Events) != 2 || agendas[0].Events[1].ID != "second" || agendas[0].Day.Day != 2 {
+		t.Errorf("unexpected first agenda %+v", agendas[0])
+	}
+	if len(agendas[1].Events) != 1 || agendas[1].Events[0].ID != "last" || !agendas[1].Until.IsZero() {
+		t.Errorf("unexpected last agenda %+v", agendas[1])
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultMaxPages is the maximum number of pages of an agenda, if not set
+const DefaultMaxPages = 10
+
+// paginate registers the callbacks requesting the next pages of the agenda, following the
+// link to the next page, or increasing the page parameter while the pages have new events,
+// up to the maximum number of pages. The events already seen in previous pages are dropped,
+// as some sites, as Drupal views, serve the last page again past the end with a new DOM id
+func (a *Agenda) paginate(c *colly.Collector) {
+	maxPages := a.MaxPages
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	pages := 1
+
+	if a.NextPageSelector != "" && !a.DoPost {
+		c.OnHTML(a.NextPageSelector, func(e *colly.HTMLElement) {
+			href := e.Request.AbsoluteURL(e.Attr("href"))
+			if href == "" || pages >= maxPages {
+				return
+			}
+
+			// the pagers at the top and the bottom of a page link the same page
+			if a.visit(c, href, "") == nil {
+				pages++
+			}
+		})
+	}
+
+	if a.PageParam == "" {
+		return
+	}
+
+	from := 0
+	seen := map[string]bool{}
+	c.OnScraped(func(r *colly.Response) {
+		found := false
+		kept := a.Events[:from]
+		for _, event := range a.Events[from:] {
+			if event.ID != "" && seen[event.ID] {
+				continue
+			}
+			seen[event.ID] = true
+			kept = append(kept, event)
+			found = true
+		}
+		a.Events = kept
+		from = len(a.Events)
+		if !found {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"url":      r.Request.URL,
+			}).Debug("No new events in the page, stopping the pagination")
+			return
+		}
+
+		if pages >= maxPages {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"pages":    pages,
+			}).Warn("Reached the maximum number of pages of the agenda")
+			return
+		}
+
+		pageURL, payload := a.page(pages)
+		pages++
+		a.visit(c, pageURL, payload)
+	})
+}
+
+// page returns the URL and the payload of a page of the agenda, from zero
+func (a *Agenda) page(page int) (string, string) {
+	if a.DoPost {
+		return a.URL, a.Payload + "&" + url.QueryEscape(a.PageParam) + "=" + strconv.Itoa(page)
+	}
+
+	pageURL, err := url.Parse(a.URL)
+	if err != nil {
+		return a.URL, ""
+	}
+
+	query := pageURL.Query()
+	query.Set(a.PageParam, strconv.Itoa(page))
+	pageURL.RawQuery = query.Encode()
+
+	return pageURL.String(), ""
+}
+
+// visit requests a page of the agenda, posting the payload in POST agendas
+func (a *Agenda) visit(c *colly.Collector, u string, payload string) error {
+	if !a.DoPost {
+		return c.Visit(u)
+	}
+
+	ctx := colly.NewContext()
+	ctx.Put("payload", payload)
+
+	return c.Request("POST", u, strings.NewReader(payload), ctx, nil)
+}
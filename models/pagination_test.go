@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPaginateStopsOnSeenEvents(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		ids := `"a", "b"`
+		if page > 0 {
+			// past the end, the last page is served again with a new DOM id
+			ids = `"c"`
+		}
+		fmt.Fprintf(w, `{"dom": %d, "ids": [%s]}`, requests, ids)
+	}))
+	defer server.Close()
+
+	a := &Agenda{
+		ID:        "test",
+		PageParam: "page",
+		Uncached:  true,
+		URL:       server.URL + "/agenda",
+		JSONProcessor: func(a *Agenda, body []byte) {
+			var response struct {
+				IDs []string `json:"ids"`
+			}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range response.IDs {
+				a.Events = append(a.Events, AgendaEvent{ID: id})
+			}
+		},
+	}
+
+	err := a.Scrap(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.Events) != 3 || a.Events[2].ID != "c" {
+		t.Errorf("unexpected events %+v", a.Events)
+	}
+	if requests != 3 {
+		t.Errorf("unexpected %d requests", requests)
+	}
+}
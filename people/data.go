@@ -0,0 +1,45 @@
+package people
+
+// bundledPeople are the presidents of the regions since their agendas were first published
+var bundledPeople = []Person{
+	{ID: "maria-dolores-de-cospedal", Name: "María Dolores de Cospedal", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Castilla-La Mancha", Start: "2011-06-22", End: "2015-07-04", Party: "PP"},
+	}},
+	{ID: "emiliano-garcia-page", Name: "Emiliano García-Page", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Castilla-La Mancha", Start: "2015-07-04", Party: "PSOE"},
+	}},
+	{ID: "juan-vicente-herrera", Name: "Juan Vicente Herrera", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Castilla-León", Start: "2001-03-16", End: "2019-07-12", Party: "PP"},
+	}},
+	{ID: "alfonso-fernandez-manueco", Name: "Alfonso Fernández Mañueco", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Castilla-León", Start: "2019-07-12", Party: "PP"},
+	}},
+	{ID: "jose-antonio-monago", Name: "José Antonio Monago", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Extremadura", Start: "2011-07-08", End: "2015-07-04", Party: "PP"},
+	}},
+	{ID: "guillermo-fernandez-vara", Name: "Guillermo Fernández Vara", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Extremadura", Start: "2007-06-30", End: "2011-07-08", Party: "PSOE"},
+		{Office: "Presidente", Region: "Extremadura", Start: "2015-07-04", End: "2023-07-17", Party: "PSOE"},
+	}},
+	{ID: "maria-guardiola", Name: "María Guardiola", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Extremadura", Start: "2023-07-17", Party: "PP"},
+	}},
+	{ID: "esperanza-aguirre", Name: "Esperanza Aguirre", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2003-11-21", End: "2012-09-27", Party: "PP"},
+	}},
+	{ID: "ignacio-gonzalez", Name: "Ignacio González", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2012-09-27", End: "2015-06-25", Party: "PP"},
+	}},
+	{ID: "cristina-cifuentes", Name: "Cristina Cifuentes", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2015-06-25", End: "2018-04-25", Party: "PP"},
+	}},
+	{ID: "pedro-rollan", Name: "Pedro Rollán", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2018-04-25", End: "2018-05-21", Party: "PP"},
+	}},
+	{ID: "angel-garrido", Name: "Ángel Garrido", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2018-05-21", End: "2019-08-19", Party: "PP"},
+	}},
+	{ID: "isabel-diaz-ayuso", Name: "Isabel Díaz Ayuso", Tenures: []Tenure{
+		{Office: "Presidente", Region: "Madrid", Start: "2019-08-19", Party: "PP"},
+	}},
+}
@@ -0,0 +1,108 @@
+package people
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Canonical roles of the owners, shared by all the regions
+const (
+	President     = "Presidente"
+	VicePresident = "Vicepresidente"
+	Councillor    = "Consejero"
+)
+
+// articleRegexp matches the leading article of an owner, as in "La Presidenta"
+var articleRegexp = regexp.MustCompile(`(?i)^(el|la)\s+`)
+
+// councillorRegexp matches the councillors and their departments, as in "Consejera de
+// Sanidad" or "Consejería de Economía, Empresas y Empleo", capturing the area
+var councillorRegexp = regexp.MustCompile(`(?i)^consejer(?:o|a|ía|ia)\s+(?:(de la|de los|de las|del|de)\s+)?(.+)$`)
+
+// vicePresidentRegexp matches the normalized offices of the vice-presidents of the regional
+// government, with their ordinal or the department they also head, if any, as
+// "vicepresidente primero" or "vicepresidente y consejero de economia"
+var vicePresidentRegexp = regexp.MustCompile(`^vicepresidente(?: (?:primer|segund|tercer|cuart)[oa])?(?: y consejero .+)?$`)
+
+// Role returns the canonical role of an owner: the president and the vice-presidents of the
+// regions whatever their gender, bare or qualified by the government of the region, as "La
+// Presidenta de la Comunidad de Madrid", and the councillors of each area, as "Consejero de
+// Sanidad". Other owners keep their name, without the leading article, so that the
+// presidents of other bodies, as "Presidente de las Cortes", are not the president
+func Role(owner string) string {
+	owner = articleRegexp.ReplaceAllString(strings.Join(strings.Fields(owner), " "), "")
+	if owner == "" {
+		return ""
+	}
+
+	office := governmentOffice(owner)
+	switch {
+	case office == "presidente":
+		return President
+	case vicePresidentRegexp.MatchString(office):
+		return VicePresident
+	}
+
+	matches := councillorRegexp.FindStringSubmatch(owner)
+	if matches != nil {
+		preposition := strings.ToLower(matches[1])
+		if preposition == "" {
+			preposition = "de"
+		}
+
+		return Councillor + " " + preposition + " " + upperFirst(strings.TrimSpace(matches[2]))
+	}
+
+	return upperFirst(owner)
+}
+
+// NormalizeEvent replaces the owner of the event with its canonical role, keeping the raw
+// owner in the original owner, and attributes the event to the person holding the role on
+// its date. Owners naming a person are replaced with the office that person held
+func (r *Registry) NormalizeEvent(event *models.AgendaEvent) {
+	if event.OriginalOwner == "" {
+		event.OriginalOwner = event.Owner
+	}
+
+	person, ok := r.Person(event.OriginalOwner)
+	if ok {
+		for _, tenure := range person.Tenures {
+			if tenure.Region == event.Region && tenure.holds(event.Date.Format("2006-01-02")) {
+				event.Owner = Role(tenure.Office)
+				event.PersonID = person.ID
+				event.Party = tenure.Party
+				return
+			}
+		}
+	}
+
+	event.Owner = Role(event.OriginalOwner)
+	r.AttributeEvent(event)
+}
+
+// Person returns the person with the name or the ID
+func (r *Registry) Person(nameOrID string) (Person, bool) {
+	key := analysis.Normalize(nameOrID)
+	if key == "" {
+		return Person{}, false
+	}
+
+	for _, person := range r.people {
+		if person.ID == nameOrID || analysis.Normalize(person.Name) == key {
+			return person, true
+		}
+	}
+
+	return Person{}, false
+}
+
+// upperFirst uppercases the first letter of a text
+func upperFirst(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
@@ -0,0 +1,51 @@
+package people
+
+import (
+	"testing"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		owner string
+		role  string
+	}{
+		{"Presidente", President},
+		{"La Presidenta", President},
+		{"El presidente", President},
+		{"Presidencia", President},
+		{"Presidenta de la Comunidad de Madrid", President},
+		{"Presidente de la Junta de Castilla y León", President},
+		{"Presidente de las Cortes", "Presidente de las Cortes"},
+		{"Presidente del Consejo Consultivo", "Presidente del Consejo Consultivo"},
+		{"La Vicepresidenta", VicePresident},
+		{"Vicepresidente Primero", VicePresident},
+		{"Vicepresidente y Consejero de Economía", VicePresident},
+		{"Vicepresidente del Consejo Económico y Social", "Vicepresidente del Consejo Económico y Social"},
+		{"Consejera de Sanidad", "Consejero de Sanidad"},
+		{"Consejería de Economía, Empresas y Empleo", "Consejero de Economía, Empresas y Empleo"},
+		{"consejero de la Presidencia", "Consejero de la Presidencia"},
+		{"el director general de Salud Pública", "Director general de Salud Pública"},
+		{"  ", ""},
+	}
+
+	for _, test := range tests {
+		if role := Role(test.owner); role != test.role {
+			t.Errorf("%q has the role %q, want %q", test.owner, role, test.role)
+		}
+	}
+}
+
+func TestNormalizeEventOtherBodies(t *testing.T) {
+	r := DefaultRegistry()
+
+	for _, owner := range []string{"Presidente de las Cortes", "Presidente del Consejo Consultivo"} {
+		event := models.AgendaEvent{Owner: owner, Region: "Castilla-La Mancha", Date: date("2020-01-01")}
+		r.NormalizeEvent(&event)
+
+		if event.Owner != owner || event.PersonID != "" || event.Party != "" {
+			t.Errorf("%q normalized to %q, %q, %q", owner, event.Owner, event.PersonID, event.Party)
+		}
+	}
+}
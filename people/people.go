@@ -0,0 +1,138 @@
+package people
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Person represents a politician, with the offices held
+type Person struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Tenures []Tenure `json:"tenures"`
+}
+
+// Tenure represents the period an office of a region was held by a person. Dates are
+// yyyy-MM-dd, the end being the first day out of the office, or empty if still held
+type Tenure struct {
+	Office string `json:"office"`
+	Region string `json:"region"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Party  string `json:"party"`
+}
+
+// officeForms are the feminine forms of the offices, and the names of their departments, so
+// that "La Presidenta" and "Presidencia" are the same office as "El Presidente"
+var officeForms = map[string]string{
+	"consejera":      "consejero",
+	"ministra":       "ministro",
+	"presidencia":    "presidente",
+	"presidenta":     "presidente",
+	"vicepresidenta": "vicepresidente",
+}
+
+// governmentRegexp matches the qualification of a normalized office by the government of
+// its region, as "de la junta de castilla y leon" or "de la comunidad de madrid", so that
+// "Presidente de la Junta" is the same office as "Presidente", but not "Presidente de las
+// Cortes"
+var governmentRegexp = regexp.MustCompile(`\s+(?:de la (?:junta|comunidad|generalitat|xunta|region|ciudad autonoma)|del (?:gobierno|principado|consell))(?:\s.*)?$`)
+
+// Registry represents the people holding the offices with public agendas
+type Registry struct {
+	people []Person
+}
+
+// NewRegistry returns a registry of people
+func NewRegistry(people []Person) *Registry {
+	return &Registry{people: people}
+}
+
+// DefaultRegistry returns the registry of the built-in people, the presidents of the regions
+func DefaultRegistry() *Registry {
+	return NewRegistry(append([]Person{}, bundledPeople...))
+}
+
+// Load reads people from a JSON file, adding them to the built-in ones
+func Load(path string) (*Registry, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	people := []Person{}
+	err = json.Unmarshal(bytes, &people)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(append(append([]Person{}, bundledPeople...), people...)), nil
+}
+
+// Holder returns the person holding an office of a region on a date, with the tenure. The
+// office may be qualified by the government of the region, as "Presidente de la Junta de
+// Castilla y León", but not by other bodies, as "Presidente de las Cortes"
+func (r *Registry) Holder(region string, office string, date time.Time) (Person, Tenure, bool) {
+	key := governmentOffice(office)
+	day := date.Format("2006-01-02")
+
+	for _, person := range r.people {
+		for _, tenure := range person.Tenures {
+			if tenure.Region != region || governmentOffice(tenure.Office) != key {
+				continue
+			}
+
+			if tenure.holds(day) {
+				return person, tenure, true
+			}
+		}
+	}
+
+	return Person{}, Tenure{}, false
+}
+
+// AttributeEvent sets the person holding the office of the owner of the event on its date,
+// and its party
+func (r *Registry) AttributeEvent(event *models.AgendaEvent) {
+	person, tenure, ok := r.Holder(event.Region, event.Owner, event.Date)
+	if !ok {
+		return
+	}
+
+	event.PersonID = person.ID
+	event.Party = tenure.Party
+}
+
+// holds checks if the office was held on a day, as yyyy-MM-dd
+func (t Tenure) holds(day string) bool {
+	return day >= t.Start && (t.End == "" || day < t.End)
+}
+
+// officeKey returns the normalized name of an office, without the leading article and with
+// the forms of the offices replaced
+func officeKey(office string) string {
+	words := strings.Fields(analysis.Normalize(office))
+	if len(words) > 0 && (words[0] == "el" || words[0] == "la") {
+		words = words[1:]
+	}
+
+	for i, word := range words {
+		if form, ok := officeForms[word]; ok {
+			words[i] = form
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// governmentOffice returns the normalized name of an office, without its qualification by
+// the government of the region, if any
+func governmentOffice(office string) string {
+	return governmentRegexp.ReplaceAllString(officeKey(office), "")
+}
@@ -0,0 +1,88 @@
+package people
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func date(day string) time.Time {
+	t, _ := time.Parse("2006-01-02", day)
+	return t
+}
+
+func TestHolder(t *testing.T) {
+	r := DefaultRegistry()
+
+	tests := []struct {
+		region string
+		office string
+		day    string
+		id     string
+	}{
+		// the end of a tenure is the first day out of the office
+		{"Castilla-La Mancha", "Presidente", "2015-07-03", "maria-dolores-de-cospedal"},
+		{"Castilla-La Mancha", "Presidente", "2015-07-04", "emiliano-garcia-page"},
+		{"Castilla-La Mancha", "Presidente", "2011-06-21", ""},
+		// the Madrid successions of 2018
+		{"Madrid", "La Presidenta", "2018-04-24", "cristina-cifuentes"},
+		{"Madrid", "La Presidenta", "2018-04-25", "pedro-rollan"},
+		{"Madrid", "La Presidenta", "2018-05-20", "pedro-rollan"},
+		{"Madrid", "La Presidenta", "2018-05-21", "angel-garrido"},
+		{"Madrid", "Presidenta de la Comunidad de Madrid", "2019-08-19", "isabel-diaz-ayuso"},
+		{"Castilla-León", "Presidente de la Junta de Castilla y León", "2019-07-12", "alfonso-fernandez-manueco"},
+		{"Extremadura", "Presidencia", "2023-07-16", "guillermo-fernandez-vara"},
+		// other bodies, and other regions
+		{"Castilla-La Mancha", "Presidente de las Cortes", "2020-01-01", ""},
+		{"Madrid", "Presidente del Consejo Consultivo", "2020-01-01", ""},
+		{"Madrid", "Vicepresidente", "2020-01-01", ""},
+		{"Aragón", "Presidente", "2020-01-01", ""},
+	}
+
+	for _, test := range tests {
+		person, _, ok := r.Holder(test.region, test.office, date(test.day))
+		if ok != (test.id != "") || person.ID != test.id {
+			t.Errorf("%s of %s on %s held by %q, want %q", test.office, test.region, test.day, person.ID, test.id)
+		}
+	}
+}
+
+func TestNormalizeEvent(t *testing.T) {
+	r := NewRegistry(append(append([]Person{}, bundledPeople...), Person{
+		ID: "enrique-ossorio", Name: "Enrique Ossorio", Tenures: []Tenure{
+			{Office: "Consejero de Educación", Region: "Madrid", Start: "2019-08-20", End: "2021-06-19", Party: "PP"},
+			{Office: "Consejero de Educación, Universidades, Ciencia y Portavocía", Region: "Madrid", Start: "2021-06-19", Party: "PP"},
+		},
+	}))
+
+	tests := []struct {
+		owner    string
+		day      string
+		role     string
+		personID string
+		party    string
+	}{
+		{"La Presidenta", "2018-04-24", President, "cristina-cifuentes", "PP"},
+		{"La Presidenta", "2018-04-25", President, "pedro-rollan", "PP"},
+		{"El Presidente", "2018-05-21", President, "angel-garrido", "PP"},
+		{"Consejería de Educación", "2020-01-01", "Consejero de Educación", "enrique-ossorio", "PP"},
+		// owners naming a person are replaced with the office held on the date
+		{"Enrique Ossorio", "2021-06-18", "Consejero de Educación", "enrique-ossorio", "PP"},
+		{"Enrique Ossorio", "2021-06-19", "Consejero de Educación, Universidades, Ciencia y Portavocía", "enrique-ossorio", "PP"},
+		{"Enrique Ossorio", "2019-08-19", "Enrique Ossorio", "", ""},
+	}
+
+	for _, test := range tests {
+		event := models.AgendaEvent{Owner: test.owner, Region: "Madrid", Date: date(test.day)}
+		r.NormalizeEvent(&event)
+
+		if event.Owner != test.role || event.PersonID != test.personID || event.Party != test.party {
+			t.Errorf("%q on %s normalized to %q, %q, %q, want %q, %q, %q", test.owner, test.day,
+				event.Owner, event.PersonID, event.Party, test.role, test.personID, test.party)
+		}
+		if event.OriginalOwner != test.owner {
+			t.Errorf("%q on %s kept %q as the original owner", test.owner, test.day, event.OriginalOwner)
+		}
+	}
+}
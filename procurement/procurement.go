@@ -0,0 +1,378 @@
+package procurement
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Award represents the award of a public contract
+type Award struct {
+	ID        string    `json:"id"`
+	Awardee   string    `json:"awardee"`
+	AwardeeID string    `json:"awardeeId"`
+	Authority string    `json:"authority"`
+	Region    string    `json:"region"`
+	Date      time.Time `json:"date"`
+	Amount    float64   `json:"amount"`
+	Object    string    `json:"object"`
+	URL       string    `json:"url"`
+}
+
+// columns of the CSV files, including the Spanish names used by the procurement platforms
+var csvColumns = map[string][]string{
+	"id":        {"id", "expediente", "numero de expediente", "identificador"},
+	"awardee":   {"awardee", "adjudicatario", "empresa adjudicataria", "nombre adjudicatario", "razon social adjudicatario"},
+	"awardeeId": {"awardee id", "nif", "cif", "nif adjudicatario", "identificador adjudicatario"},
+	"authority": {"authority", "organo de contratacion", "organo contratante"},
+	"region":    {"region", "comunidad autonoma", "ccaa"},
+	"date":      {"date", "fecha", "fecha adjudicacion", "fecha de adjudicacion"},
+	"amount":    {"amount", "importe", "importe adjudicacion", "importe de adjudicacion", "importe adjudicado"},
+	"object":    {"object", "objeto", "objeto del contrato", "descripcion"},
+	"url":       {"url", "enlace", "link"},
+}
+
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006", "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05"}
+
+// thousandsRegexp matches amounts with dots as thousands separators, as "1.234.567"
+var thousandsRegexp = regexp.MustCompile(`^\d{1,3}(\.\d{3})+$`)
+
+// regionAliases are the normalised names of the regions, as written by the procurement
+// platforms and their contracting authorities, keyed by the name of the region in the agendas
+var regionAliases = map[string][]string{
+	"Castilla-La Mancha": {"castilla la mancha", "junta de comunidades de castilla la mancha", "clm"},
+	"Castilla-León":      {"castilla leon", "castilla y leon", "junta de castilla y leon", "cyl"},
+	"Extremadura":        {"extremadura", "junta de extremadura"},
+	"Madrid":             {"madrid", "comunidad de madrid"},
+}
+
+// RegionName returns the name of the region in the agendas for a name used by the awards,
+// as "Castilla-León" for "Castilla y León", or the name if it is not known
+func RegionName(name string) string {
+	normalized := analysis.Normalize(name)
+	for region, aliases := range regionAliases {
+		if normalized == analysis.Normalize(region) {
+			return region
+		}
+		for _, alias := range aliases {
+			if normalized == alias {
+				return region
+			}
+		}
+	}
+
+	return name
+}
+
+// authorityRegion returns the region of the agendas a contracting authority belongs to, as
+// "Castilla-León" for "Consejería de Sanidad de la Junta de Castilla y León", if any. Single
+// words, as "madrid", are not enough, as they also name city councils
+func authorityRegion(authority string) string {
+	normalized := " " + analysis.Normalize(authority) + " "
+	for region, aliases := range regionAliases {
+		for _, alias := range aliases {
+			if strings.Contains(alias, " ") && strings.Contains(normalized, " "+alias+" ") {
+				return region
+			}
+		}
+	}
+
+	return ""
+}
+
+// Load reads the awards from a CSV file. Awards without region are assigned to the region
+// of their contracting authority or, if unknown, to the region. Awards left without region
+// are discarded, as they cannot be matched with the events of the same administration
+func Load(path string, region string) ([]Award, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	awards, err := ParseCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Award{}
+	for _, award := range awards {
+		if award.Region == "" {
+			award.Region = authorityRegion(award.Authority)
+		}
+		if award.Region == "" {
+			award.Region = region
+		}
+		if award.Region == "" {
+			log.WithFields(log.Fields{
+				"award":     award.ID,
+				"authority": award.Authority,
+			}).Warn("Award without region, discarding it")
+			continue
+		}
+
+		award.Region = RegionName(award.Region)
+		result = append(result, award)
+	}
+
+	return result, nil
+}
+
+// ParseCSV reads the awards from CSV with a header row including, at least, the awardee
+// and the date of the awards. Both comma and semicolon separators are supported. Rows with
+// an invalid date or amount are discarded
+func ParseCSV(r io.Reader) ([]Award, error) {
+	buffered := bufio.NewReader(r)
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	header := string(firstLine)
+	if i := strings.Index(header, "\n"); i >= 0 {
+		header = header[:i]
+	}
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []Award{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		normalized := analysis.Normalize(name)
+		for field, names := range csvColumns {
+			for _, n := range names {
+				if normalized == n {
+					if _, exists := columns[field]; !exists {
+						columns[field] = i
+					}
+				}
+			}
+		}
+	}
+
+	for _, required := range []string{"awardee", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the CSV header does not include the %s column: %v", required, records[0])
+		}
+	}
+
+	value := func(record []string, field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	awards := []Award{}
+	for i, record := range records[1:] {
+		award := Award{
+			ID:        value(record, "id"),
+			Awardee:   value(record, "awardee"),
+			AwardeeID: value(record, "awardeeId"),
+			Authority: value(record, "authority"),
+			Region:    value(record, "region"),
+			Object:    value(record, "object"),
+			URL:       value(record, "url"),
+		}
+		if award.Awardee == "" {
+			continue
+		}
+		if award.ID == "" {
+			award.ID = strconv.Itoa(i + 1)
+		}
+
+		award.Date, err = parseDate(value(record, "date"))
+		if err == nil {
+			if amount := value(record, "amount"); amount != "" {
+				award.Amount, err = parseAmount(amount)
+			}
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"line":  i + 2,
+				"error": err,
+			}).Warn("Invalid award, discarding it")
+			continue
+		}
+
+		awards = append(awards, award)
+	}
+
+	return awards, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unsupported date %q", value)
+}
+
+// parseAmount parses amounts in Spanish format, as "1.234,56 €" or "1.234.567", or in plain
+// format, as "1234.56"
+func parseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "€"))
+	value = strings.ReplaceAll(value, " ", "")
+	if strings.Contains(value, ",") || thousandsRegexp.MatchString(value) {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported amount %q", value)
+	}
+
+	return amount, nil
+}
+
+// Kinds of entries of a timeline
+const (
+	AwardEntry   = "award"
+	MeetingEntry = "meeting"
+)
+
+// Entry represents a meeting or an award in the timeline of an organization
+type Entry struct {
+	Date time.Time `json:"date"`
+	Kind string    `json:"kind"`
+	// Owner of the event, or contracting authority of the award
+	Owner  string `json:"owner"`
+	Region string `json:"region"`
+	// Days from the meeting to the award it was found for, negative if the meeting was after it
+	Days        int     `json:"days"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	// Link is the ID of the event, or the URL of the award
+	Link string `json:"link"`
+	// SourceURL is the URL of the page the event was scraped from, if recorded
+	SourceURL string `json:"sourceURL,omitempty"`
+}
+
+// Timeline represents the meetings of an organization around the awards it received
+type Timeline struct {
+	Organization string  `json:"organization"`
+	Entries      []Entry `json:"entries"`
+}
+
+// Timelines returns, per awardee, the awards it received and the events of the same region
+// mentioning it within the days before or after each award, if any. Awardees are matched
+// against the organizations of the events by name similarity, and the regions of both by
+// their known names. Awards without region match no event
+func Timelines(awards []Award, events []models.AgendaEvent, days int, threshold float64) []Timeline {
+	// events by mentioned organization, to compare each name only once
+	byOrganization := map[string][]int{}
+	for i, event := range events {
+		for _, organization := range event.Organizations {
+			byOrganization[organization.Name] = append(byOrganization[organization.Name], i)
+		}
+	}
+
+	window := time.Duration(days) * 24 * time.Hour
+	matches := map[string][]string{}
+
+	timelines := map[string]*Timeline{}
+	withMeetings := map[string]bool{}
+	seenMeetings := map[string]map[int]bool{}
+	for _, award := range awards {
+		names, ok := matches[award.Awardee]
+		if !ok {
+			names = []string{}
+			for name := range byOrganization {
+				if analysis.OrganizationSimilarity(name, award.Awardee) >= threshold {
+					names = append(names, name)
+				}
+			}
+			matches[award.Awardee] = names
+		}
+
+		timeline, ok := timelines[award.Awardee]
+		if !ok {
+			timeline = &Timeline{Organization: award.Awardee, Entries: []Entry{}}
+			timelines[award.Awardee] = timeline
+			seenMeetings[award.Awardee] = map[int]bool{}
+		}
+
+		timeline.Entries = append(timeline.Entries, Entry{
+			Date:        award.Date,
+			Kind:        AwardEntry,
+			Owner:       award.Authority,
+			Region:      award.Region,
+			Amount:      award.Amount,
+			Description: award.Object,
+			Link:        award.URL,
+		})
+
+		for _, name := range names {
+			for _, i := range byOrganization[name] {
+				event := events[i]
+				if award.Region == "" || RegionName(award.Region) != RegionName(event.Region) {
+					continue
+				}
+
+				diff := award.Date.Sub(event.Date)
+				if diff > window || diff < -window || seenMeetings[award.Awardee][i] {
+					continue
+				}
+				seenMeetings[award.Awardee][i] = true
+				withMeetings[award.Awardee] = true
+
+				timeline.Entries = append(timeline.Entries, Entry{
+					Date:        event.Date,
+					Kind:        MeetingEntry,
+					Owner:       event.Owner,
+					Region:      event.Region,
+					Days:        int(diff.Hours() / 24),
+					Description: event.OriginalDescription,
+					Link:        event.ID,
+					SourceURL:   event.SourceURL,
+				})
+			}
+		}
+	}
+
+	result := []Timeline{}
+	for awardee, timeline := range timelines {
+		if !withMeetings[awardee] {
+			continue
+		}
+
+		sort.SliceStable(timeline.Entries, func(i, j int) bool {
+			return timeline.Entries[i].Date.Before(timeline.Entries[j].Date)
+		})
+		result = append(result, *timeline)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Organization < result[j].Organization
+	})
+
+	return result
+}
@@ -0,0 +1,115 @@
+package procurement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value  string
+		amount float64
+	}{
+		{"1.234,56 €", 1234.56},
+		{"1.234.567", 1234567},
+		{"1.234", 1234},
+		{"1234.56", 1234.56},
+		{"12 345,00", 12345},
+		{"500", 500},
+	}
+
+	for _, test := range tests {
+		amount, err := parseAmount(test.value)
+		if err != nil || amount != test.amount {
+			t.Errorf("parseAmount(%q) = %v, %v, want %v", test.value, amount, err, test.amount)
+		}
+	}
+
+	if _, err := parseAmount("mil euros"); err == nil {
+		t.Error("parseAmount accepted an invalid amount")
+	}
+}
+
+func TestParseCSVSkipsInvalidRows(t *testing.T) {
+	csv := "Expediente;Adjudicatario;Fecha adjudicación;Importe;Órgano de contratación\n" +
+		"1;Abengoa;2020-06-01;1.234.567;Junta de Castilla y León\n" +
+		"2;Iberdrola;;1.000;Junta de Extremadura\n" +
+		"3;Indra;2020-06-03;mil;Comunidad de Madrid\n" +
+		"4;Telefónica;03/06/2020;2.500,50 €;\n"
+
+	awards, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(awards) != 2 || awards[0].ID != "1" || awards[1].ID != "4" {
+		t.Fatalf("unexpected awards %+v", awards)
+	}
+	if awards[0].Amount != 1234567 || awards[1].Amount != 2500.5 {
+		t.Errorf("unexpected amounts %v and %v", awards[0].Amount, awards[1].Amount)
+	}
+}
+
+func TestRegionName(t *testing.T) {
+	tests := map[string]string{
+		"Castilla y León":     "Castilla-León",
+		"CASTILLA-LEÓN":       "Castilla-León",
+		"Castilla La Mancha":  "Castilla-La Mancha",
+		"Comunidad de Madrid": "Madrid",
+		"Galicia":             "Galicia",
+	}
+
+	for name, region := range tests {
+		if got := RegionName(name); got != region {
+			t.Errorf("RegionName(%q) = %q, want %q", name, got, region)
+		}
+	}
+
+	if region := authorityRegion("Consejería de Sanidad de la Junta de Castilla y León"); region != "Castilla-León" {
+		t.Errorf("unexpected authority region %q", region)
+	}
+	if region := authorityRegion("Ayuntamiento de Madrid"); region != "" {
+		t.Errorf("unexpected authority region %q", region)
+	}
+}
+
+func TestTimelines(t *testing.T) {
+	date := time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC)
+	event := func(id string, region string) models.AgendaEvent {
+		return models.AgendaEvent{
+			ID:            id,
+			Date:          date.AddDate(0, 0, -5),
+			Owner:         "Presidente",
+			Region:        region,
+			SourceURL:     "https://" + id + ".example.org/agenda",
+			Organizations: []models.Organization{{Name: "Abengoa"}},
+		}
+	}
+	events := []models.AgendaEvent{event("cyl", "Castilla-León"), event("clm", "Castilla-La Mancha")}
+
+	awards := []Award{
+		{ID: "1", Awardee: "Abengoa, S.A.", Region: "Castilla y León", Date: date, Authority: "Junta de Castilla y León"},
+		{ID: "2", Awardee: "Abengoa, S.A.", Date: date},
+	}
+
+	timelines := Timelines(awards, events, 30, 0.9)
+	if len(timelines) != 1 {
+		t.Fatalf("unexpected timelines %+v", timelines)
+	}
+
+	meetings := []string{}
+	for _, entry := range timelines[0].Entries {
+		if entry.Kind == MeetingEntry {
+			meetings = append(meetings, entry.Link)
+			if entry.SourceURL != "https://cyl.example.org/agenda" {
+				t.Errorf("unexpected source URL %q", entry.SourceURL)
+			}
+		}
+	}
+	if len(meetings) != 1 || meetings[0] != "cyl" {
+		t.Errorf("unexpected meetings %v", meetings)
+	}
+}
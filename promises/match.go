@@ -0,0 +1,94 @@
+package promises
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Match represents an event related to a promise
+type Match struct {
+	EventID     string    `json:"eventId"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Owner       string    `json:"owner"`
+	Region      string    `json:"region"`
+	Score       float64   `json:"score"`
+}
+
+// Activity represents the agenda activity related to a promise
+type Activity struct {
+	Promise Promise   `json:"promise"`
+	Events  int       `json:"events"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+	Matches []Match   `json:"matches"`
+}
+
+// Report scores each event against each promise with the TF-IDF cosine similarity of
+// their texts, and returns the activity of each promise considering only the events
+// scoring at least the threshold. The activities are sorted by number of events
+func Report(promises []Promise, events []models.AgendaEvent, threshold float64) []Activity {
+	documents := make([][]string, 0, len(promises)+len(events))
+	for _, promise := range promises {
+		documents = append(documents, analysis.Tokenize(promise.Section+" "+promise.Text))
+	}
+	for _, event := range events {
+		documents = append(documents, analysis.Tokenize(event.OriginalDescription))
+	}
+
+	corpus := analysis.NewCorpus(documents)
+
+	eventVectors := make([]analysis.Vector, len(events))
+	for i := range events {
+		eventVectors[i] = corpus.Vector(documents[len(promises)+i])
+	}
+
+	activities := make([]Activity, len(promises))
+	for i, promise := range promises {
+		promiseVector := corpus.Vector(documents[i])
+
+		activity := Activity{
+			Promise: promise,
+			Matches: []Match{},
+		}
+
+		for j, event := range events {
+			score := analysis.Cosine(promiseVector, eventVectors[j])
+			if score < threshold {
+				continue
+			}
+
+			activity.Matches = append(activity.Matches, Match{
+				EventID:     event.ID,
+				Date:        event.Date,
+				Description: event.OriginalDescription,
+				Owner:       event.Owner,
+				Region:      event.Region,
+				Score:       score,
+			})
+
+			if activity.First.IsZero() || event.Date.Before(activity.First) {
+				activity.First = event.Date
+			}
+			if event.Date.After(activity.Last) {
+				activity.Last = event.Date
+			}
+		}
+
+		sort.Slice(activity.Matches, func(a, b int) bool {
+			return activity.Matches[a].Date.Before(activity.Matches[b].Date)
+		})
+
+		activity.Events = len(activity.Matches)
+		activities[i] = activity
+	}
+
+	sort.SliceStable(activities, func(a, b int) bool {
+		return activities[a].Events > activities[b].Events
+	})
+
+	return activities
+}
@@ -0,0 +1,44 @@
+package promises
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestReport(t *testing.T) {
+	promises := []Promise{
+		{ID: "1", Section: "Sanidad", Text: "Construiremos el nuevo hospital universitario de Toledo"},
+		{ID: "2", Section: "Agricultura", Text: "Modernizaremos los regadíos de La Mancha"},
+		{ID: "3", Section: "Cultura", Text: "Abriremos una biblioteca en cada comarca"},
+	}
+
+	date := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+	events := []models.AgendaEvent{
+		{ID: "later", Date: date.AddDate(0, 1, 0), OriginalDescription: "Visita a las obras del hospital universitario de Toledo"},
+		{ID: "first", Date: date, OriginalDescription: "Inauguración del nuevo hospital de Toledo"},
+		{ID: "other", Date: date, OriginalDescription: "Reunión con los regantes sobre los regadíos"},
+		{ID: "none", Date: date, OriginalDescription: "Consejo de Gobierno"},
+	}
+
+	activities := Report(promises, events, 0.2)
+	if len(activities) != 3 {
+		t.Fatalf("unexpected activities %+v", activities)
+	}
+
+	top := activities[0]
+	if top.Promise.ID != "1" || top.Events != 2 || top.Matches[0].EventID != "first" || top.Matches[1].EventID != "later" {
+		t.Errorf("unexpected top activity %+v", top)
+	}
+	if !top.First.Equal(date) || !top.Last.Equal(date.AddDate(0, 1, 0)) {
+		t.Errorf("unexpected dates %v - %v", top.First, top.Last)
+	}
+
+	if activities[1].Promise.ID != "2" || activities[1].Events != 1 || activities[1].Matches[0].EventID != "other" {
+		t.Errorf("unexpected activity %+v", activities[1])
+	}
+	if activities[2].Promise.ID != "3" || activities[2].Events != 0 {
+		t.Errorf("unexpected activity without events %+v", activities[2])
+	}
+}
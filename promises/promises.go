@@ -0,0 +1,163 @@
+package promises
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Promise represents a political promise, as an item of a manifesto or a speech
+type Promise struct {
+	ID      string `json:"id"`
+	Section string `json:"section"`
+	Text    string `json:"text"`
+}
+
+var listItemRegexp = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+(.*)$`)
+
+// Load reads a corpus of promises from a Markdown, CSV or JSON file, based on its extension
+func Load(path string) ([]Promise, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return ParseMarkdown(file)
+	case ".csv":
+		return ParseCSV(file)
+	case ".json":
+		bytes, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+
+		promises := []Promise{}
+		err = json.Unmarshal(bytes, &promises)
+		return promises, err
+	}
+
+	return nil, fmt.Errorf("unsupported promises file %s. Please use Markdown, CSV or JSON", path)
+}
+
+// ParseMarkdown reads the promises from Markdown, where each list item is a promise and
+// headings are the section of the promises below them. If there are no list items,
+// each paragraph is a promise
+func ParseMarkdown(r io.Reader) ([]Promise, error) {
+	items := []Promise{}
+	paragraphs := []Promise{}
+
+	section := ""
+	var item *Promise
+	var paragraph *Promise
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			item = nil
+			paragraph = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			section = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			item = nil
+			paragraph = nil
+			continue
+		}
+
+		if matches := listItemRegexp.FindStringSubmatch(line); matches != nil {
+			items = append(items, Promise{Section: section, Text: matches[2]})
+			item = &items[len(items)-1]
+			paragraph = nil
+			continue
+		}
+
+		if item != nil {
+			// continuation of a list item
+			item.Text += " " + line
+			continue
+		}
+
+		if paragraph != nil {
+			paragraph.Text += " " + line
+			continue
+		}
+
+		paragraphs = append(paragraphs, Promise{Section: section, Text: line})
+		paragraph = &paragraphs[len(paragraphs)-1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		items = paragraphs
+	}
+
+	for i := range items {
+		items[i].ID = fmt.Sprintf("%03d", i+1)
+	}
+
+	return items, nil
+}
+
+// ParseCSV reads the promises from CSV with a header row including a "text" column, and
+// optionally "id" and "section" columns. Promises without ID are numbered sequentially
+func ParseCSV(r io.Reader) ([]Promise, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []Promise{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	textColumn, ok := columns["text"]
+	if !ok {
+		return nil, fmt.Errorf("the CSV header does not include a text column: %v", records[0])
+	}
+
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	promises := []Promise{}
+	for i, record := range records[1:] {
+		if textColumn >= len(record) || strings.TrimSpace(record[textColumn]) == "" {
+			continue
+		}
+
+		promise := Promise{
+			ID:      value(record, "id"),
+			Section: value(record, "section"),
+			Text:    strings.TrimSpace(record[textColumn]),
+		}
+		if promise.ID == "" {
+			promise.ID = fmt.Sprintf("%03d", i+1)
+		}
+
+		promises = append(promises, promise)
+	}
+
+	return promises, nil
+}
@@ -0,0 +1,88 @@
+package promises
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	markdown := `# Programa electoral
+
+Introducción que no es una promesa.
+
+## Sanidad
+
+- Construiremos el nuevo hospital
+  de Toledo.
+- Reduciremos las listas de espera.
+
+## Educación
+
+1. Becas para todos los alumnos.
+2) Gratuidad de los libros de texto.
+`
+
+	promises, err := ParseMarkdown(strings.NewReader(markdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Promise{
+		{ID: "001", Section: "Sanidad", Text: "Construiremos el nuevo hospital de Toledo."},
+		{ID: "002", Section: "Sanidad", Text: "Reduciremos las listas de espera."},
+		{ID: "003", Section: "Educación", Text: "Becas para todos los alumnos."},
+		{ID: "004", Section: "Educación", Text: "Gratuidad de los libros de texto."},
+	}
+	if !reflect.DeepEqual(promises, expected) {
+		t.Errorf("unexpected promises %+v", promises)
+	}
+}
+
+func TestParseMarkdownParagraphs(t *testing.T) {
+	markdown := `# Discurso de investidura
+
+Vamos a construir el nuevo hospital
+de Toledo.
+
+Vamos a reducir las listas de espera.
+`
+
+	promises, err := ParseMarkdown(strings.NewReader(markdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Promise{
+		{ID: "001", Section: "Discurso de investidura", Text: "Vamos a construir el nuevo hospital de Toledo."},
+		{ID: "002", Section: "Discurso de investidura", Text: "Vamos a reducir las listas de espera."},
+	}
+	if !reflect.DeepEqual(promises, expected) {
+		t.Errorf("unexpected promises %+v", promises)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	csv := "ID,Section,Text\n" +
+		"S1,Sanidad,Construiremos el nuevo hospital de Toledo\n" +
+		",Educación,Becas para todos los alumnos\n" +
+		"S3,Sanidad,\n"
+
+	promises, err := ParseCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Promise{
+		{ID: "S1", Section: "Sanidad", Text: "Construiremos el nuevo hospital de Toledo"},
+		{ID: "002", Section: "Educación", Text: "Becas para todos los alumnos"},
+	}
+	if !reflect.DeepEqual(promises, expected) {
+		t.Errorf("unexpected promises %+v", promises)
+	}
+
+	_, err = ParseCSV(strings.NewReader("id,section\n1,Sanidad\n"))
+	if err == nil {
+		t.Error("expected an error for the missing text column")
+	}
+}
@@ -2,19 +2,30 @@ package regions
 
 import (
 	"fmt"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/holidays"
 	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
 )
 
 const clmClass = "agenda evento"
 
-const clmCurrentEventsURL = "https://transparencia.castillalamancha.es/agenda/198?date_filter[value][date]=%02d/%02d/%04d"
+const clmCurrentEventsURL = "https://transparencia.castillalamancha.es/agenda/%s?date_filter[value][date]=%02d/%02d/%04d"
 
-const clmPastEventsURL = "https://transparencia.castillalamancha.es/agenda-historico/198?date_filter[value][date]=%02d/%02d/%04d"
+const clmPastEventsURL = "https://transparencia.castillalamancha.es/agenda-historico/%s?date_filter[value][date]=%02d/%02d/%04d"
+
+// clmOwnersURL lists the agendas of the government, linked by the ID of each official, as
+// "/agenda/198", which changes with each government
+const clmOwnersURL = "https://transparencia.castillalamancha.es/agenda"
+
+var clmOwnerRegexp = regexp.MustCompile(`/agenda/(\d+)(?:[/?#]|$)`)
+
+// clmPresident is the owner of the agenda of the president
+var clmPresident = models.Owner{ID: "198", Name: "Presidente"}
 
 var clmCurrentStartDate = models.AgendaDate{
 	Day: 8, Month: 7, Year: 2019,
@@ -34,23 +45,35 @@ func CLM() *models.Region {
 		Name:      "Castilla-La Mancha",
 		DoPost:    false,
 		StartDate: clmHistoricalStartDate,
+		Holidays:  holidays.NewCalendar("Castilla-La Mancha"),
+		Owners:    []models.Owner{clmPresident},
+		DiscoverOwners: func(delay time.Duration) ([]models.Owner, error) {
+			return discoverOwners(clmOwnersURL, clmOwnerRegexp, delay)
+		},
 	}
 }
 
 // NewAgendaCLM represents the agenda for Castilla-la Mancha
-func NewAgendaCLM(region *models.Region, day int, month int, year int) *models.Agenda {
+func NewAgendaCLM(region *models.Region, owner models.Owner, day int, month int, year int) (*models.Agenda, error) {
 	agendaDate := models.AgendaDate{
 		Day: day, Month: month, Year: year,
 	}
 
 	agendaURL := clmPastEventsURL
 	cssSelector := "div.agenda-historico div div ul"
+	urlVariant := "historical"
+	nextPageSelector := ""
 	if agendaDate.ToDate().After(clmHistoricalEndDate.ToDate()) {
 		agendaURL = clmCurrentEventsURL
 		cssSelector = "div.view-agenda div div ul"
+		urlVariant = "current"
+		nextPageSelector = "ul.pager li.pager-next a"
 	}
 
-	loc, _ := time.LoadLocation("Europe/Madrid")
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
 
 	dateTime := time.Date(
 		agendaDate.Year, time.Month(agendaDate.Month), agendaDate.Day,
@@ -58,21 +81,23 @@ func NewAgendaCLM(region *models.Region, day int, month int, year int) *models.A
 	)
 
 	agendaCLM := &models.Agenda{
-		AllowedDomains: []string{"transparencia.castillalamancha.es"},
-		HTMLSelector:   cssSelector,
-		HTMLProcessor:  clmProcessor,
-		URLFormat:      agendaURL,
-		Date:           dateTime,
-		Day:            agendaDate,
-		DoPost:         region.DoPost,
-		Events:         []models.AgendaEvent{},
-		ID:             "clm-" + dateTime.Local().Format("2006-01-02"),
-		Owner:          "Presidente",
-		Region:         region.Name,
-		URL:            fmt.Sprintf(agendaURL, agendaDate.Day, agendaDate.Month, agendaDate.Year),
+		AllowedDomains:   []string{"transparencia.castillalamancha.es"},
+		HTMLSelector:     cssSelector,
+		HTMLProcessor:    clmProcessor,
+		URLFormat:        agendaURL,
+		URLVariant:       urlVariant,
+		NextPageSelector: nextPageSelector,
+		Date:             dateTime,
+		Day:              agendaDate,
+		DoPost:           region.DoPost,
+		Events:           []models.AgendaEvent{},
+		ID:               "clm-" + owner.ID + "-" + dateTime.Local().Format("2006-01-02"),
+		Owner:            owner.Name,
+		Region:           region.Name,
+		URL:              fmt.Sprintf(agendaURL, owner.ID, agendaDate.Day, agendaDate.Month, agendaDate.Year),
 	}
 
-	return agendaCLM
+	return agendaCLM, nil
 }
 
 func clmProcessor(a *models.Agenda, e *colly.HTMLElement) {
@@ -86,29 +111,9 @@ func clmProcessor(a *models.Agenda, e *colly.HTMLElement) {
 					Region:     a.Region,
 				}
 			} else if index == 1 {
-				description := li.Text
-				firstHyphen := strings.Index(description, "-")
-
-				descRunes := []rune(description)
-				dateString := string(descRunes[0:firstHyphen])
-
-				dateTime := strings.Split(dateString, ":")
-				hour, err := strconv.Atoi(dateTime[0])
-				if err != nil {
-					hour = 0
-				}
-				minString := dateTime[1]
-				min, err := strconv.Atoi(strings.Split(minString, " ")[0])
-				if err != nil {
-					min = 0
-				}
-				loc, _ := time.LoadLocation("Europe/Madrid")
-
-				event.Date = time.Date(
-					a.Day.Year, a.Day.ToDate().Month(), a.Day.Day,
-					hour, min, 0, 0, loc,
-				)
-				event.Description = strings.TrimSpace(string(descRunes[firstHyphen+1:]))
+				expression, description := splitTime(li.Text)
+				setEventTime(a, &event, expression)
+				event.Description = description
 				event.OriginalDescription = event.Description
 			} else if index == 2 {
 				location := li.Text
@@ -149,7 +154,7 @@ func clmProcessor(a *models.Agenda, e *colly.HTMLElement) {
 				// discard LI
 			}
 		})
-		event.ID = "clm-" + event.Date.Local().Format("2006-01-02T15:04:05-0700")
+		event.ID = eventID("clm", clmPresident.Name, &event)
 		a.Events = append(a.Events, event)
 	}
 }
@@ -2,16 +2,20 @@ package regions
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/holidays"
 	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
 )
 
 const cylEventsURL = "https://comunicacion.jcyl.es/web/jcyl/Comunicacion/es/PlantillaCalendarioBuscadorComponente/1284877983791/_/_/_?param[0]=%04d&param[1]=%02d&param[2]=%02d&parametro2=1281372093473&parametro3=1284233390583"
 
+// cylPresident is the owner of the agenda of the government, whose events keep their
+// officials
+const cylPresident = "Presidente"
+
 var juntaCYLStartDate = models.AgendaDate{
 	Day: 21, Month: 11, Year: 2012,
 }
@@ -22,11 +26,15 @@ func CYL() *models.Region {
 		Name:      "Castilla-León",
 		DoPost:    false,
 		StartDate: juntaCYLStartDate,
+		Holidays:  holidays.NewCalendar("Castilla-León"),
+		Owners: []models.Owner{
+			{Name: cylPresident},
+		},
 	}
 }
 
 // NewAgendaCYL represents the agenda for CYL
-func NewAgendaCYL(region *models.Region, day int, month int, year int) *models.Agenda {
+func NewAgendaCYL(region *models.Region, owner models.Owner, day int, month int, year int) (*models.Agenda, error) {
 	agendaDate := models.AgendaDate{
 		Day: day, Month: month, Year: year,
 	}
@@ -34,7 +42,10 @@ func NewAgendaCYL(region *models.Region, day int, month int, year int) *models.A
 	agendaURL := cylEventsURL
 	cssSelector := "#contenidos"
 
-	loc, _ := time.LoadLocation("Europe/Madrid")
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
 
 	dateTime := time.Date(
 		agendaDate.Year, time.Month(agendaDate.Month), agendaDate.Day,
@@ -42,21 +53,23 @@ func NewAgendaCYL(region *models.Region, day int, month int, year int) *models.A
 	)
 
 	agendaCYL := &models.Agenda{
-		AllowedDomains: []string{"comunicacion.jcyl.es"},
-		HTMLSelector:   cssSelector,
-		HTMLProcessor:  cylProcessor,
-		URLFormat:      agendaURL,
-		Date:           dateTime,
-		Day:            agendaDate,
-		DoPost:         region.DoPost,
-		Events:         []models.AgendaEvent{},
-		ID:             "cyl-" + dateTime.Local().Format("2006-01-02"),
-		Owner:          "Presidente",
-		Region:         region.Name,
-		URL:            fmt.Sprintf(agendaURL, agendaDate.Year, agendaDate.Month, agendaDate.Day),
+		AllowedDomains:  []string{"comunicacion.jcyl.es"},
+		HTMLSelector:    cssSelector,
+		HTMLProcessor:   cylProcessor,
+		DetailSelector:  "#contenidos",
+		DetailProcessor: detailProcessor,
+		URLFormat:       agendaURL,
+		Date:            dateTime,
+		Day:             agendaDate,
+		DoPost:          region.DoPost,
+		Events:          []models.AgendaEvent{},
+		ID:              "cyl-" + dateTime.Local().Format("2006-01-02"),
+		Owner:           owner.Name,
+		Region:          region.Name,
+		URL:             fmt.Sprintf(agendaURL, agendaDate.Year, agendaDate.Month, agendaDate.Day),
 	}
 
-	return agendaCYL
+	return agendaCYL, nil
 }
 
 func cylProcessor(a *models.Agenda, e *colly.HTMLElement) {
@@ -64,6 +77,7 @@ func cylProcessor(a *models.Agenda, e *colly.HTMLElement) {
 		ul.ForEach("li.destacada a", func(index int, anchor *colly.HTMLElement) {
 			var event = models.AgendaEvent{
 				Attendance: []models.Attendee{},
+				DetailURL:  absoluteURL(anchor.Request.URL.String(), anchor.Attr("href")),
 				Owner:      a.Owner,
 				Region:     a.Region,
 			}
@@ -71,24 +85,10 @@ func cylProcessor(a *models.Agenda, e *colly.HTMLElement) {
 			anchor.ForEach("span", func(index int, span *colly.HTMLElement) {
 				switch spanClass := span.Attr("class"); spanClass {
 				case "fecha":
-					hour := 0
-					min := 0
-					loc, _ := time.LoadLocation("Europe/Madrid")
+					setEventTime(a, &event, "")
 					span.ForEach("span.hora", func(index int, timeSpan *colly.HTMLElement) {
-						dateString := timeSpan.Text
-
-						dateTime := strings.Split(dateString, ":")
-						hour, _ = strconv.Atoi(dateTime[0])
-
-						minString := dateTime[1]
-						minString = strings.ReplaceAll(minString, "h", "")
-						min, _ = strconv.Atoi(strings.Split(minString, " ")[0])
+						setEventTime(a, &event, timeSpan.Text)
 					})
-
-					event.Date = time.Date(
-						a.Day.Year, a.Day.ToDate().Month(), a.Day.Day,
-						hour, min, 0, 0, loc,
-					)
 				case "subtitulo":
 					event.Owner = span.Text
 				case "lugar":
@@ -107,7 +107,7 @@ func cylProcessor(a *models.Agenda, e *colly.HTMLElement) {
 				}
 			})
 
-			event.ID = "cyl-" + event.Date.Local().Format("2006-01-02T15:04:05-0700")
+			event.ID = eventID("cyl", cylPresident, &event)
 			a.Events = append(a.Events, event)
 		})
 	})
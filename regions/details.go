@@ -0,0 +1,113 @@
+package regions
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	models "github.com/mdelapenya/cansino/models"
+)
+
+// attachmentExtensions are the extensions of the documents attached to the detail pages
+var attachmentExtensions = []string{".pdf", ".doc", ".docx", ".odt", ".xls", ".xlsx", ".ods", ".ppt", ".pptx", ".zip"}
+
+// pressReleaseTerms are the terms of the links to press releases, in their texts or URLs
+var pressReleaseTerms = []string{"nota de prensa", "notas de prensa", "notas-de-prensa", "nota-de-prensa", "noticia", "comunicado"}
+
+// detailProcessor merges the detail page of an event into the event: the text of its
+// paragraphs as the full description, its images as photos, its linked documents as
+// attachments, and the first link to a press release
+func detailProcessor(event *models.AgendaEvent, e *colly.HTMLElement) {
+	paragraphs := []string{}
+	e.ForEach("p", func(_ int, p *colly.HTMLElement) {
+		text := strings.Join(strings.Fields(p.Text), " ")
+		if text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	})
+	if len(paragraphs) == 0 {
+		paragraphs = append(paragraphs, strings.Join(strings.Fields(e.Text), " "))
+	}
+	event.FullDescription = strings.Join(paragraphs, "\n")
+
+	e.ForEach("img[src]", func(_ int, img *colly.HTMLElement) {
+		event.Photos = appendURL(event.Photos, img.Request.AbsoluteURL(img.Attr("src")))
+	})
+
+	e.ForEach("a[href]", func(_ int, anchor *colly.HTMLElement) {
+		href := anchor.Request.AbsoluteURL(anchor.Attr("href"))
+		if href == "" {
+			return
+		}
+
+		if isAttachment(href) {
+			event.Attachments = appendURL(event.Attachments, href)
+		} else if event.PressReleaseURL == "" && isPressRelease(anchor.Text, href) {
+			event.PressReleaseURL = href
+		}
+	})
+}
+
+// absoluteURL resolves a link of a page into an absolute URL, or returns an empty string
+// if the link is not valid
+func absoluteURL(base string, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+
+	hrefURL, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+
+	return baseURL.ResolveReference(hrefURL).String()
+}
+
+// appendURL appends an URL to a list, if not empty nor already in the list
+func appendURL(urls []string, u string) []string {
+	if u == "" {
+		return urls
+	}
+
+	for _, existing := range urls {
+		if existing == u {
+			return urls
+		}
+	}
+
+	return append(urls, u)
+}
+
+func isAttachment(href string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+
+	extension := strings.ToLower(path.Ext(u.Path))
+	for _, attachmentExtension := range attachmentExtensions {
+		if extension == attachmentExtension {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPressRelease(text string, href string) bool {
+	text = strings.ToLower(text + " " + href)
+	for _, term := range pressReleaseTerms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+
+	return false
+}
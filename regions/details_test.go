@@ -0,0 +1,133 @@
+package regions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// detailPage is a detail page of an event, as the ones of Castilla y León
+const detailPage = `<html><body>
+	<div id="menu"><p>Inicio</p><a href="/noticias/portada">Portada</a></div>
+	<div id="contenidos">
+		<h1>Reunión con el sector agrario</h1>
+		<p>  El consejero se reúne con
+			las organizaciones agrarias. </p>
+		<p></p>
+		<p>Asistirá el director general de Política Agraria.</p>
+		<img src="/fotos/reunion.jpg">
+		<img src="https://comunicacion.jcyl.es/fotos/reunion.jpg">
+		<img src="fotos/detalle.png">
+		<a href="/documentos/orden-del-dia.PDF?version=2">Orden del día</a>
+		<a href="/documentos/orden-del-dia.PDF?version=2">Orden del día</a>
+		<a href="https://www.jcyl.es/anexo.docx">Anexo</a>
+		<a href="/web/jcyl/Comunicacion/es/Plantilla100Detalle/1284877983892/NotaPrensa/1285">Nota de prensa</a>
+		<a href="/web/es/notas-de-prensa/otra.html">Otra</a>
+		<a href="">Vacío</a>
+	</div>
+</body></html>`
+
+func TestDetailProcessor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, detailPage)
+	}))
+	defer server.Close()
+
+	event := models.AgendaEvent{}
+	c := colly.NewCollector()
+	c.OnHTML("#contenidos", func(e *colly.HTMLElement) {
+		detailProcessor(&event, e)
+	})
+	if err := c.Visit(server.URL + "/agenda/evento/"); err != nil {
+		t.Fatal(err)
+	}
+
+	description := "El consejero se reúne con las organizaciones agrarias.\nAsistirá el director general de Política Agraria."
+	if event.FullDescription != description {
+		t.Errorf("unexpected description %q", event.FullDescription)
+	}
+
+	photos := []string{server.URL + "/fotos/reunion.jpg", "https://comunicacion.jcyl.es/fotos/reunion.jpg", server.URL + "/agenda/evento/fotos/detalle.png"}
+	if fmt.Sprint(event.Photos) != fmt.Sprint(photos) {
+		t.Errorf("unexpected photos %v", event.Photos)
+	}
+
+	attachments := []string{server.URL + "/documentos/orden-del-dia.PDF?version=2", "https://www.jcyl.es/anexo.docx"}
+	if fmt.Sprint(event.Attachments) != fmt.Sprint(attachments) {
+		t.Errorf("unexpected attachments %v", event.Attachments)
+	}
+
+	pressRelease := server.URL + "/web/jcyl/Comunicacion/es/Plantilla100Detalle/1284877983892/NotaPrensa/1285"
+	if event.PressReleaseURL != pressRelease {
+		t.Errorf("unexpected press release %q", event.PressReleaseURL)
+	}
+}
+
+func TestDetailProcessorWithoutParagraphs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><div about="/evento"> Visita a la
+			feria de Zafra </div></body></html>`)
+	}))
+	defer server.Close()
+
+	event := models.AgendaEvent{}
+	c := colly.NewCollector()
+	c.OnHTML("div[about]", func(e *colly.HTMLElement) {
+		detailProcessor(&event, e)
+	})
+	if err := c.Visit(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if event.FullDescription != "Visita a la feria de Zafra" {
+		t.Errorf("unexpected description %q", event.FullDescription)
+	}
+	if len(event.Photos) != 0 || len(event.Attachments) != 0 || event.PressReleaseURL != "" {
+		t.Errorf("unexpected media %+v", event)
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	tests := []struct {
+		base     string
+		href     string
+		expected string
+	}{
+		{"https://www.comunidad.madrid/agenda", "/actividad/visita", "https://www.comunidad.madrid/actividad/visita"},
+		{"https://www.comunidad.madrid/agenda/", "visita", "https://www.comunidad.madrid/agenda/visita"},
+		{"https://www.comunidad.madrid/agenda", " https://www.madrid.org/visita ", "https://www.madrid.org/visita"},
+		{"https://www.comunidad.madrid/agenda", "?page=2", "https://www.comunidad.madrid/agenda?page=2"},
+		{"https://www.comunidad.madrid/agenda", "  ", ""},
+		{"https://www.comunidad.madrid/agenda", "http://[::1", ""},
+		{"://comunidad", "/visita", ""},
+	}
+
+	for _, test := range tests {
+		if actual := absoluteURL(test.base, test.href); actual != test.expected {
+			t.Errorf("%q from %q resolved to %q, want %q", test.href, test.base, actual, test.expected)
+		}
+	}
+}
+
+func TestIsAttachment(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.jcyl.es/documentos/orden.pdf":           true,
+		"https://www.jcyl.es/documentos/ORDEN.PDF?version=2": true,
+		"https://www.jcyl.es/documentos/presupuestos.xlsx":   true,
+		"https://www.jcyl.es/documentos/anexos.zip#inicio":   true,
+		"https://www.jcyl.es/noticias/orden.html":            false,
+		"https://www.jcyl.es/descargar?fichero=orden.pdf":    false,
+		"https://www.jcyl.es/documentos/":                    false,
+		"http://[::1":                                        false,
+	}
+
+	for href, expected := range tests {
+		if actual := isAttachment(href); actual != expected {
+			t.Errorf("%q is an attachment: %v, want %v", href, actual, expected)
+		}
+	}
+}
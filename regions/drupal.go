@@ -0,0 +1,245 @@
+package regions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// DrupalView represents an agenda published with a Drupal view, requested to the AJAX
+// endpoint of the views, as "https://www.comunidad.madrid/views/ajax", filtering the events
+// by a range of days with its exposed filters
+type DrupalView struct {
+	URL       string `json:"url"`
+	ViewName  string `json:"viewName"`
+	DisplayID string `json:"displayId"`
+	// FromFilter and UntilFilter are the exposed filters of the first and the last day, as
+	// "field_date_value[value][date]"
+	FromFilter  string `json:"fromFilter"`
+	UntilFilter string `json:"untilFilter"`
+	// DateFormat is the layout of the days in the filters, 02/01/2006 by default
+	DateFormat string `json:"dateFormat"`
+	// Filters are other exposed filters, with their values
+	Filters map[string]string `json:"filters"`
+	// Events is the XPath of the events in the HTML fragment of the view
+	Events string `json:"events"`
+	// DetailSelector selects the content of the detail pages of the events, if linked
+	DetailSelector string       `json:"detailSelector"`
+	Fields         DrupalFields `json:"fields"`
+}
+
+// DrupalFields maps the fields of the events to the nodes of each event of the view
+type DrupalFields struct {
+	Owner XPathField `json:"owner"`
+	// Date is the time expression of the event, starting with its day in ranges of days
+	Date XPathField `json:"date"`
+	// Description is built from the fields, joined with hyphens, as the title and the summary
+	Description []XPathField `json:"description"`
+	Location    XPathField   `json:"location"`
+	DetailURL   XPathField   `json:"detailURL"`
+}
+
+// XPathField maps a field to the text, or an attribute, of the first node matching any of
+// its XPaths, in order, removing the prefixes of the text, as "Lugar: "
+type XPathField struct {
+	XPaths   []string `json:"xpaths"`
+	Attr     string   `json:"attr"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// NewAgendaDrupal represents the agenda of a source published with a Drupal view from a
+// day until another one
+func NewAgendaDrupal(region *models.Region, owner models.Owner, source *Source, from time.Time, until time.Time) (*models.Agenda, error) {
+	view := source.Drupal
+
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	dateTime := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	untilTime := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, loc)
+
+	agendaDate := models.AgendaDate{
+		Day: dateTime.Day(), Month: int(dateTime.Month()), Year: dateTime.Year(),
+	}
+
+	return &models.Agenda{
+		AllowedDomains:  source.AllowedDomains,
+		JSONProcessor:   view.processor(source.IDPrefix, president(region)),
+		DetailSelector:  view.DetailSelector,
+		DetailProcessor: detailProcessor,
+		PageParam:       "page",
+		URLFormat:       view.URL,
+		Date:            dateTime,
+		Day:             agendaDate,
+		DoPost:          true,
+		Payload:         view.payload(dateTime, untilTime),
+		Events:          []models.AgendaEvent{},
+		ID:              source.IDPrefix + "-" + dateTime.Local().Format("2006-01-02"),
+		Owner:           owner.Name,
+		Region:          region.Name,
+		Until:           untilTime,
+		URL:             view.URL,
+	}, nil
+}
+
+// payload returns the form of the AJAX request of the events from a day until another one
+func (v *DrupalView) payload(from time.Time, until time.Time) string {
+	layout := v.DateFormat
+	if layout == "" {
+		layout = "02/01/2006"
+	}
+
+	params := url.Values{}
+	for name, value := range v.Filters {
+		params.Set(name, value)
+	}
+	params.Set(v.FromFilter, from.Local().Format(layout))
+	params.Set(v.UntilFilter, until.Local().Format(layout))
+	params.Set("view_name", v.ViewName)
+	params.Set("view_display_id", v.DisplayID)
+
+	return params.Encode()
+}
+
+// processor returns the processor of the AJAX responses of the view, reading the events of
+// the HTML fragment of its insert command with the XPaths of the fields
+func (v *DrupalView) processor(idPrefix string, president string) func(a *models.Agenda, body []byte) {
+	return func(a *models.Agenda, body []byte) {
+		data, err := drupalInsertData(body)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"error":    err,
+			}).Error("Cannot read the response of the view")
+			return
+		}
+
+		doc, err := htmlquery.Parse(strings.NewReader(data))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"error":    err,
+			}).Error("Cannot parse the HTML of the view")
+			return
+		}
+
+		for _, node := range htmlquery.Find(doc, v.Events) {
+			owner := v.Fields.Owner.text(node)
+			if owner == "" {
+				owner = a.Owner
+			}
+
+			event := models.AgendaEvent{
+				Attendance: []models.Attendee{},
+				DetailURL:  absoluteURL(v.URL, v.Fields.DetailURL.text(node)),
+				Owner:      owner,
+				Region:     a.Region,
+			}
+
+			err := setEventDateTime(a, &event, v.Fields.Date.text(node))
+			if err != nil {
+				log.WithFields(log.Fields{
+					"agendaID": a.ID,
+					"error":    err,
+				}).Warn("Cannot parse the date of the event. Discarding it")
+				continue
+			}
+
+			description := []string{}
+			for _, field := range v.Fields.Description {
+				if text := field.text(node); text != "" {
+					description = append(description, text)
+				}
+			}
+			event.Description = strings.Join(description, " - ")
+			event.OriginalDescription = event.Description
+
+			event.Location = v.Fields.Location.text(node)
+			event.OriginalLocation = event.Location
+
+			event.ID = eventID(idPrefix, president, &event)
+			a.Events = append(a.Events, event)
+		}
+	}
+}
+
+// drupalInsertData returns the HTML fragment of the insert command of an AJAX response of a
+// Drupal view, which is a list of commands, whatever its position in the list
+func drupalInsertData(body []byte) (string, error) {
+	commands := []map[string]interface{}{}
+	err := json.Unmarshal(body, &commands)
+	if err != nil {
+		return "", err
+	}
+
+	for _, command := range commands {
+		if command["command"] != "insert" {
+			continue
+		}
+
+		if data, ok := command["data"].(string); ok && strings.TrimSpace(data) != "" {
+			return data, nil
+		}
+	}
+
+	// views without results may not insert anything
+	return "", nil
+}
+
+// text returns the text of the field in the node of an event, or an empty string if not
+// found
+func (f XPathField) text(node *html.Node) string {
+	for _, expr := range f.XPaths {
+		found := htmlquery.FindOne(node, expr)
+		if found == nil {
+			continue
+		}
+
+		text := htmlquery.InnerText(found)
+		if f.Attr != "" {
+			text = htmlquery.SelectAttr(found, f.Attr)
+		}
+
+		for _, prefix := range f.Prefixes {
+			text = strings.ReplaceAll(text, prefix, "")
+		}
+
+		return strings.TrimSpace(text)
+	}
+
+	return ""
+}
+
+func (v *DrupalView) validate() error {
+	if v.URL == "" || v.ViewName == "" {
+		return fmt.Errorf("the Drupal view has no URL or view name")
+	}
+	if v.FromFilter == "" || v.UntilFilter == "" {
+		return fmt.Errorf("the Drupal view has no filters of the days")
+	}
+
+	expressions := []string{v.Events}
+	for _, field := range append([]XPathField{v.Fields.Owner, v.Fields.Date, v.Fields.Location, v.Fields.DetailURL}, v.Fields.Description...) {
+		expressions = append(expressions, field.XPaths...)
+	}
+
+	for _, expr := range expressions {
+		_, err := xpath.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("wrong XPath %q of the Drupal view: %v", expr, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package regions
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrupalPayload(t *testing.T) {
+	view := &DrupalView{
+		ViewName:    "agenda&view",
+		DisplayID:   "block",
+		FromFilter:  "field_date_value[value][date]",
+		UntilFilter: "field_date_value2[value][date]",
+		Filters:     map[string]string{"type[]": "acto & reunión"},
+	}
+
+	payload := view.payload(time.Date(2020, 3, 2, 0, 0, 0, 0, time.Local), time.Date(2020, 3, 8, 0, 0, 0, 0, time.Local))
+	if strings.Contains(payload, "[") || strings.Contains(payload, "/") {
+		t.Errorf("unescaped payload %s", payload)
+	}
+
+	values, err := url.ParseQuery(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"field_date_value[value][date]":  "02/03/2020",
+		"field_date_value2[value][date]": "08/03/2020",
+		"type[]":                         "acto & reunión",
+		"view_name":                      "agenda&view",
+		"view_display_id":                "block",
+	}
+	if len(values) != len(expected) {
+		t.Errorf("unexpected payload %v", values)
+	}
+	for name, value := range expected {
+		if values.Get(name) != value {
+			t.Errorf("expected %s=%q, got %q", name, value, values.Get(name))
+		}
+	}
+}
+
+func TestDrupalValidate(t *testing.T) {
+	err := madridSource.validate()
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	view := *madridSource.Drupal
+	view.Fields.Description = []XPathField{{XPaths: []string{"//div[contains(@class, 'title')"}}}
+	if err := view.validate(); err == nil {
+		t.Error("expected an error for the wrong XPath of the description")
+	}
+
+	view = *madridSource.Drupal
+	view.Events = ""
+	if err := view.validate(); err == nil {
+		t.Error("expected an error for the missing XPath of the events")
+	}
+}
+
+func TestMadridDayByDay(t *testing.T) {
+	region := Madrid()
+	from := time.Date(2020, 3, 2, 0, 0, 0, 0, time.Local)
+	until := BatchEnd(region.Granularity, from, from.AddDate(0, 1, 0))
+	if !until.Equal(from) {
+		t.Fatalf("Madrid requested until %v, want the day %v only", until, from)
+	}
+
+	agenda, err := AgendaRangeFactory(region, region.Owners[0], from, until)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the insert command of the view, whose date field has the time only
+	body, err := json.Marshal([]map[string]string{{"command": "insert", "data": `<div class="view-content">
+		<div about="/actividad/2020/03/02/visita">
+			<div class="field-name-title">Visita al hospital Gregorio Marañón</div>
+			<div class="field-name-field-counselings">Consejería de Sanidad</div>
+			<div class="field-type-date">10:00</div>
+			<div class="field-name-field-place">Lugar: Hospital Gregorio Marañón</div>
+		</div>
+	</div>`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agenda.JSONProcessor(agenda, body)
+	if len(agenda.Events) != 1 {
+		t.Fatalf("unexpected events %+v", agenda.Events)
+	}
+
+	event := agenda.Events[0]
+	if !event.Date.Equal(agenda.Date.Add(10*time.Hour)) || event.Owner != "Consejería de Sanidad" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.DetailURL != "https://www.comunidad.madrid/actividad/2020/03/02/visita" {
+		t.Errorf("unexpected detail URL %q", event.DetailURL)
+	}
+}
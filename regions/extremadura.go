@@ -3,31 +3,47 @@ package regions
 import (
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/holidays"
 	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
 )
 
-const juntaExtremaduraEventsURL = "http://www.juntaex.es/web/agenda-presidencia?year=%04d&month=%02d&day=%02d"
+// juntaExtremaduraEventsURL is the agenda of each official, as "agenda-presidencia"
+const juntaExtremaduraEventsURL = "http://www.juntaex.es/web/agenda-%s?year=%04d&month=%02d&day=%02d"
+
+// juntaExtremaduraOwnersURL links the agendas of the officials of the government
+const juntaExtremaduraOwnersURL = "http://www.juntaex.es/web/agenda-presidencia"
+
+var juntaExtremaduraOwnerRegexp = regexp.MustCompile(`/web/agenda-([\w-]+)(?:[/?#]|$)`)
+
+// juntaExtremaduraPresident is the owner of the agenda of the president
+var juntaExtremaduraPresident = models.Owner{ID: "presidencia", Name: "Presidente"}
 
 var juntaExtremaduraStartDate = models.AgendaDate{
 	Day: 1, Month: 3, Year: 2012,
 }
 
-// Extremadura returns the Extremadura region
+// Extremadura returns the Extremadura region. Its agendas are requested day by day, as the
+// pages of the site have the events of the day of the URL only, with no range of days
 func Extremadura() *models.Region {
 	return &models.Region{
 		Name:      "Extremadura",
 		DoPost:    false,
 		StartDate: juntaExtremaduraStartDate,
+		Holidays:  holidays.NewCalendar("Extremadura"),
+		Owners:    []models.Owner{juntaExtremaduraPresident},
+		DiscoverOwners: func(delay time.Duration) ([]models.Owner, error) {
+			return discoverOwners(juntaExtremaduraOwnersURL, juntaExtremaduraOwnerRegexp, delay)
+		},
 	}
 }
 
 // NewAgendaExtremadura represents the agenda for Extremadura
-func NewAgendaExtremadura(region *models.Region, day int, month int, year int) *models.Agenda {
+func NewAgendaExtremadura(region *models.Region, owner models.Owner, day int, month int, year int) (*models.Agenda, error) {
 	agendaDate := models.AgendaDate{
 		Day: day, Month: month, Year: year,
 	}
@@ -35,7 +51,10 @@ func NewAgendaExtremadura(region *models.Region, day int, month int, year int) *
 	agendaURL := juntaExtremaduraEventsURL
 	cssSelector := "#mainContent"
 
-	loc, _ := time.LoadLocation("Europe/Madrid")
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
 
 	dateTime := time.Date(
 		agendaDate.Year, time.Month(agendaDate.Month), agendaDate.Day,
@@ -51,13 +70,13 @@ func NewAgendaExtremadura(region *models.Region, day int, month int, year int) *
 		Day:            agendaDate,
 		DoPost:         region.DoPost,
 		Events:         []models.AgendaEvent{},
-		ID:             "extremadura-" + dateTime.Local().Format("2006-01-02"),
-		Owner:          "Presidente",
+		ID:             "extremadura-" + owner.ID + "-" + dateTime.Local().Format("2006-01-02"),
+		Owner:          owner.Name,
 		Region:         region.Name,
-		URL:            fmt.Sprintf(agendaURL, agendaDate.Year, agendaDate.Month, agendaDate.Day),
+		URL:            fmt.Sprintf(agendaURL, owner.ID, agendaDate.Year, agendaDate.Month, agendaDate.Day),
 	}
 
-	return agendaExtremadura
+	return agendaExtremadura, nil
 }
 
 func juntaExtremaduraProcessor(a *models.Agenda, e *colly.HTMLElement) {
@@ -73,28 +92,10 @@ func juntaExtremaduraProcessor(a *models.Agenda, e *colly.HTMLElement) {
 				header := headerDiv.Text
 				header = strings.ReplaceAll(header, "\t", "")
 				header = strings.ReplaceAll(header, "\n", "")
-				firstHyphen := strings.Index(header, "-")
-
-				headerRunes := []rune(header)
-				dateString := string(headerRunes[0:firstHyphen])
-
-				dateTime := strings.Split(dateString, ":")
-				hour, err := strconv.Atoi(dateTime[0])
-				if err != nil {
-					hour = 0
-				}
-				minString := dateTime[1]
-				min, err := strconv.Atoi(strings.Split(minString, " ")[0])
-				if err != nil {
-					min = 0
-				}
-				loc, _ := time.LoadLocation("Europe/Madrid")
 
-				event.Date = time.Date(
-					a.Day.Year, a.Day.ToDate().Month(), a.Day.Day,
-					hour, min, 0, 0, loc,
-				)
-				event.Location = strings.TrimSpace(string(headerRunes[firstHyphen+1:]))
+				expression, location := splitTime(header)
+				setEventTime(a, &event, expression)
+				event.Location = location
 				event.OriginalLocation = event.Location
 			}
 
@@ -119,8 +120,6 @@ func juntaExtremaduraProcessor(a *models.Agenda, e *colly.HTMLElement) {
 					matches := re.FindAllStringSubmatch(description, 4)
 					if len(matches) == 1 && len(matches[0]) == 5 {
 						description = strings.TrimSpace(matches[0][4])
-					} else {
-						description = description
 					}
 				}
 
@@ -128,7 +127,7 @@ func juntaExtremaduraProcessor(a *models.Agenda, e *colly.HTMLElement) {
 				event.OriginalDescription = event.Description
 			})
 
-			event.ID = "extremadura-" + event.Date.Local().Format("2006-01-02T15:04:05-0700")
+			event.ID = eventID("extremadura", juntaExtremaduraPresident.Name, &event)
 			a.Events = append(a.Events, event)
 		})
 	})
@@ -0,0 +1,204 @@
+package regions
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/ical"
+	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+	log "github.com/sirupsen/logrus"
+)
+
+// Properties of the events of the feeds with their owners
+const (
+	CategoriesOwner = "categories"
+	OrganizerOwner  = "organizer"
+)
+
+// ICSFeed represents an agenda published as an iCalendar feed, from an URL or a file
+type ICSFeed struct {
+	URL string `json:"url"`
+	// OwnerProperty is the property of the events with their owners, the categories or the
+	// organizer, or empty if all the events belong to the owner of the agenda
+	OwnerProperty string `json:"ownerProperty"`
+	// Owners maps the values of the owner property, as the email of the organizer, to the
+	// owners. Values not mapped are the owners, if not empty
+	Owners map[string]string `json:"owners"`
+}
+
+// NewAgendaICS represents the agenda of a source published as an iCalendar feed, with the
+// events from a day until another one
+func NewAgendaICS(region *models.Region, owner models.Owner, source *Source, from time.Time, until time.Time) (*models.Agenda, error) {
+	feed := source.ICS
+
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	dateTime := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	untilTime := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, loc)
+
+	agendaDate := models.AgendaDate{
+		Day: dateTime.Day(), Month: int(dateTime.Month()), Year: dateTime.Year(),
+	}
+
+	feedURL, err := feed.location()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Agenda{
+		AllowedDomains: source.AllowedDomains,
+		JSONProcessor:  feed.processor(source.IDPrefix, president(region), loc),
+		LocalFile:      strings.HasPrefix(feedURL, "file:"),
+		URLFormat:      feed.URL,
+		Date:           dateTime,
+		Day:            agendaDate,
+		Events:         []models.AgendaEvent{},
+		ID:             source.IDPrefix + "-" + dateTime.Local().Format("2006-01-02"),
+		Owner:          owner.Name,
+		Region:         region.Name,
+		Uncached:       true,
+		Until:          untilTime,
+		URL:            feedURL,
+	}, nil
+}
+
+// location returns the URL of the feed, converting the paths of files into file URLs
+func (f *ICSFeed) location() (string, error) {
+	if strings.Contains(f.URL, "://") {
+		return f.URL, nil
+	}
+
+	path, err := filepath.Abs(f.URL)
+	if err != nil {
+		return "", err
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), nil
+}
+
+// processor returns the processor of the feed, reading its events from the first day of the
+// agenda until its last one. Recurring events are expanded into their occurrences in these
+// days, but the ones replaced by other events of the feed, as the occurrences moved to other
+// times. Cancelled events are discarded
+func (f *ICSFeed) processor(idPrefix string, president string, loc *time.Location) func(a *models.Agenda, body []byte) {
+	return func(a *models.Agenda, body []byte) {
+		events, err := ical.Parse(bytes.NewReader(body), loc)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"error":    err,
+			}).Error("Cannot parse the feed")
+			return
+		}
+
+		last := a.Until
+		if last.IsZero() {
+			last = a.Date
+		}
+		last = last.AddDate(0, 0, 1)
+
+		replaced := map[string][]time.Time{}
+		for _, icsEvent := range events {
+			if !icsEvent.RecurrenceID.IsZero() {
+				replaced[icsEvent.UID] = append(replaced[icsEvent.UID], icsEvent.RecurrenceID)
+			}
+		}
+
+		for _, icsEvent := range events {
+			if icsEvent.Status == "CANCELLED" {
+				continue
+			}
+
+			if icsEvent.RRule != "" && icsEvent.RecurrenceID.IsZero() {
+				icsEvent.ExDates = append(icsEvent.ExDates, replaced[icsEvent.UID]...)
+			}
+
+			occurrences, err := icsEvent.Occurrences(a.Date, last)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"agendaID": a.ID,
+					"uid":      icsEvent.UID,
+					"rrule":    icsEvent.RRule,
+					"error":    err,
+				}).Error("Cannot expand the recurring event. Discarding it")
+				continue
+			}
+
+			for _, occurrence := range occurrences {
+				f.addEvent(a, idPrefix, president, loc, occurrence)
+			}
+		}
+	}
+}
+
+// addEvent adds an event of the feed to the agenda
+func (f *ICSFeed) addEvent(a *models.Agenda, idPrefix string, president string, loc *time.Location, icsEvent ical.Event) {
+	start := icsEvent.Start.In(loc)
+
+	event := models.AgendaEvent{
+		Attendance:      []models.Attendee{},
+		AllDay:          icsEvent.AllDay,
+		Date:            start,
+		Description:     icsEvent.Summary,
+		DetailURL:       icsEvent.URL,
+		FullDescription: icsEvent.Description,
+		Location:        icsEvent.Location,
+		Owner:           f.owner(a, icsEvent),
+		Region:          a.Region,
+		TimePrecision:   models.MinutePrecision,
+	}
+	event.OriginalDescription = event.Description
+	event.OriginalLocation = event.Location
+
+	if icsEvent.AllDay {
+		event.TimePrecision = models.DayPrecision
+	} else if !icsEvent.End.IsZero() {
+		end := icsEvent.End.In(loc)
+		event.EndDate = &end
+	}
+
+	for _, attendee := range icsEvent.Attendees {
+		name := attendee.Name
+		if name == "" {
+			name = attendee.Email
+		}
+
+		event.Attendance = append(event.Attendance, models.Attendee{FullName: name})
+	}
+
+	event.ID = eventID(idPrefix, president, &event)
+	a.Events = append(a.Events, event)
+}
+
+// owner returns the owner of an event of the feed, from its owner property, or the owner of
+// the agenda. Organizers not mapped are named by their common names, if any
+func (f *ICSFeed) owner(a *models.Agenda, event ical.Event) string {
+	values := []string{}
+	switch f.OwnerProperty {
+	case CategoriesOwner:
+		values = event.Categories
+	case OrganizerOwner:
+		values = []string{event.Organizer.Name, event.Organizer.Email}
+	}
+
+	for _, value := range values {
+		if owner, ok := f.Owners[value]; ok {
+			return owner
+		}
+	}
+
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return a.Owner
+}
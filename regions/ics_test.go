@@ -0,0 +1,92 @@
+package regions
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+// icsFeed has a weekly meeting since February, with an occurrence moved and another one
+// cancelled in March, and a yearly event, whose rule is not supported
+const icsFeed = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:consejo\r\n" +
+	"SUMMARY:Consejo de Gobierno\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20200204T100000\r\n" +
+	"DTEND;TZID=Europe/Madrid:20200204T120000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=TU\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:consejo\r\n" +
+	"SUMMARY:Consejo de Gobierno extraordinario\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Madrid:20200310T100000\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20200311T090000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:consejo\r\n" +
+	"SUMMARY:Consejo de Gobierno\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Madrid:20200317T100000\r\n" +
+	"DTSTART;TZID=Europe/Madrid:20200317T100000\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:aniversario\r\n" +
+	"SUMMARY:Aniversario del Estatuto\r\n" +
+	"DTSTART;VALUE=DATE:20190310\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestICSRecurringEvents(t *testing.T) {
+	region := &models.Region{Name: "Feed", Owners: []models.Owner{{Name: "Presidente"}}}
+	source := &Source{Region: "Feed", Type: ICSSource, IDPrefix: "feed", ICS: &ICSFeed{URL: "https://example.com/agenda.ics"}}
+
+	from := time.Date(2020, 3, 9, 0, 0, 0, 0, time.Local)
+	agenda, err := NewAgendaICS(region, region.Owners[0], source, from, from.AddDate(0, 0, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agenda.LocalFile {
+		t.Error("the feed of an URL was read as a local file")
+	}
+
+	agenda.JSONProcessor(agenda, []byte(icsFeed))
+	sort.Slice(agenda.Events, func(i, j int) bool {
+		return agenda.Events[i].Date.Before(agenda.Events[j].Date)
+	})
+
+	expected := []struct {
+		date        string
+		description string
+	}{
+		{"2020-03-11 09:00", "Consejo de Gobierno extraordinario"},
+		{"2020-03-24 10:00", "Consejo de Gobierno"},
+	}
+	if len(agenda.Events) != 2 {
+		t.Fatalf("unexpected events %+v", agenda.Events)
+	}
+	for i, event := range agenda.Events {
+		if date := event.Date.Format("2006-01-02 15:04"); date != expected[i].date || event.Description != expected[i].description {
+			t.Errorf("unexpected event on %s: %q", date, event.Description)
+		}
+	}
+	if end := agenda.Events[1].EndDate; end == nil || end.Format("15:04") != "12:00" {
+		t.Errorf("unexpected end %v", end)
+	}
+}
+
+func TestICSLocalFile(t *testing.T) {
+	region := &models.Region{Name: "Feed"}
+	source := &Source{Region: "Feed", Type: ICSSource, IDPrefix: "feed", ICS: &ICSFeed{URL: "testdata/agenda.ics"}}
+
+	agenda, err := NewAgendaICS(region, models.Owner{}, source, time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !agenda.LocalFile || agenda.URL[:8] != "file:///" {
+		t.Errorf("the feed of a file was not read as a local file: %s", agenda.URL)
+	}
+}
@@ -0,0 +1,394 @@
+package regions
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	jmespath "github.com/jmespath/go-jmespath"
+	models "github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+	log "github.com/sirupsen/logrus"
+)
+
+// Transforms of the values of the fields of the JSON APIs
+const (
+	LowerTransform     = "lower"
+	StripTagsTransform = "stripTags"
+	TitleTransform     = "title"
+	UpperTransform     = "upper"
+)
+
+// tagRegexp matches the HTML tags of the values
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// JSONAPI represents an agenda published by a JSON API, as a CKAN datastore or a Socrata
+// dataset, requested with the range of days in the URL, or in the payload of POST APIs
+type JSONAPI struct {
+	// URL is the template of the URL of the API, with the {from} and {until} placeholders
+	URL string `json:"url"`
+	// Payload is the template of the body of POST APIs, with the same placeholders
+	Payload string `json:"payload"`
+	// DateFormat is the layout of the days of the placeholders, 2006-01-02 by default
+	DateFormat string `json:"dateFormat"`
+	// Events is the JMESPath expression of the list of events in the response, as
+	// "result.records", or empty if the response is the list
+	Events string `json:"events"`
+	// PageParam is the zero-based page parameter of the paginated APIs
+	PageParam string     `json:"pageParam"`
+	Fields    JSONFields `json:"fields"`
+}
+
+// JSONFields maps the fields of the events to the values of each event of the API
+type JSONFields struct {
+	Owner JSONField `json:"owner"`
+	// Date is the day of the event, and its time if the layout has it. Without layout, it is
+	// a Spanish date and time expression, as "lunes, 2 de marzo de 2020, 10:00"
+	Date JSONField `json:"date"`
+	// Time is the time expression of the event, as "10:00 - 12:00"
+	Time    JSONField `json:"time"`
+	EndDate JSONField `json:"endDate"`
+	// Description is built from the fields, joined with hyphens, as the title and the summary
+	Description     []JSONField `json:"description"`
+	FullDescription JSONField   `json:"fullDescription"`
+	Location        JSONField   `json:"location"`
+	DetailURL       JSONField   `json:"detailURL"`
+	// Attendees is a list of attendees, or a text with the attendees separated by commas
+	Attendees JSONField `json:"attendees"`
+}
+
+// JSONField maps a field to the value of its JMESPath expression in an event, as
+// "fields.title" or "attendees[0]", with its transforms, and the values mapped to others
+type JSONField struct {
+	Path string `json:"path"`
+	// Item is the JMESPath expression of the value of each item of lists, as "name"
+	Item string `json:"item"`
+	// Layout is the Go layout of dates, as "2006-01-02T15:04:05"
+	Layout     string            `json:"layout"`
+	Prefixes   []string          `json:"prefixes"`
+	Transforms []string          `json:"transforms"`
+	Map        map[string]string `json:"map"`
+}
+
+// NewAgendaJSON represents the agenda of a source published by a JSON API, with the events
+// from a day until another one
+func NewAgendaJSON(region *models.Region, owner models.Owner, source *Source, from time.Time, until time.Time) (*models.Agenda, error) {
+	api := source.JSON
+
+	loc, err := timeparse.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	dateTime := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	untilTime := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, loc)
+
+	agendaDate := models.AgendaDate{
+		Day: dateTime.Day(), Month: int(dateTime.Month()), Year: dateTime.Year(),
+	}
+
+	return &models.Agenda{
+		AllowedDomains: source.AllowedDomains,
+		JSONProcessor:  api.processor(source.IDPrefix, president(region), loc),
+		PageParam:      api.PageParam,
+		URLFormat:      api.URL,
+		Date:           dateTime,
+		Day:            agendaDate,
+		DoPost:         api.Payload != "",
+		Payload:        api.expand(api.Payload, dateTime, untilTime),
+		Events:         []models.AgendaEvent{},
+		ID:             source.IDPrefix + "-" + dateTime.Local().Format("2006-01-02"),
+		Owner:          owner.Name,
+		Region:         region.Name,
+		Until:          untilTime,
+		URL:            api.expand(api.URL, dateTime, untilTime),
+	}, nil
+}
+
+// expand replaces the {from} and {until} placeholders of a template with the days
+func (api *JSONAPI) expand(template string, from time.Time, until time.Time) string {
+	layout := api.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+
+	return strings.NewReplacer(
+		"{from}", from.Local().Format(layout),
+		"{until}", until.Local().Format(layout),
+	).Replace(template)
+}
+
+// processor returns the processor of the responses of the API, reading the events of its
+// list with the mappings of the fields, from the first day of the agenda until its last one
+func (api *JSONAPI) processor(idPrefix string, president string, loc *time.Location) func(a *models.Agenda, body []byte) {
+	return func(a *models.Agenda, body []byte) {
+		var response interface{}
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"error":    err,
+			}).Error("Cannot read the response of the API")
+			return
+		}
+
+		last := a.Until
+		if last.IsZero() {
+			last = a.Date
+		}
+		last = last.AddDate(0, 0, 1)
+
+		items, ok := search(response, api.Events).([]interface{})
+		if !ok {
+			log.WithFields(log.Fields{
+				"agendaID": a.ID,
+				"events":   api.Events,
+			}).Error("The response of the API has no list of events")
+			return
+		}
+
+		for _, item := range items {
+			owner := api.Fields.Owner.text(item)
+			if owner == "" {
+				owner = a.Owner
+			}
+
+			event := models.AgendaEvent{
+				Attendance:      []models.Attendee{},
+				DetailURL:       api.Fields.DetailURL.text(item),
+				FullDescription: api.Fields.FullDescription.text(item),
+				Owner:           owner,
+				Region:          a.Region,
+			}
+
+			err := api.setTime(a, &event, item, loc)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"agendaID": a.ID,
+					"error":    err,
+				}).Warn("Cannot parse the date of the event. Discarding it")
+				continue
+			}
+			if event.Date.Before(a.Date) || !event.Date.Before(last) {
+				log.WithFields(log.Fields{
+					"agendaID": a.ID,
+					"date":     event.Date,
+				}).Debug("Event out of the days of the agenda. Discarding it")
+				continue
+			}
+
+			description := []string{}
+			for _, field := range api.Fields.Description {
+				if text := field.text(item); text != "" {
+					description = append(description, text)
+				}
+			}
+			event.Description = strings.Join(description, " - ")
+			event.OriginalDescription = event.Description
+
+			event.Location = api.Fields.Location.text(item)
+			event.OriginalLocation = event.Location
+
+			for _, attendee := range api.Fields.Attendees.texts(item) {
+				event.Attendance = append(event.Attendance, models.Attendee{FullName: attendee})
+			}
+
+			event.ID = eventID(idPrefix, president, &event)
+			a.Events = append(a.Events, event)
+		}
+	}
+}
+
+// setTime sets the date, the end date and the time precision of an event of the API
+func (api *JSONAPI) setTime(a *models.Agenda, event *models.AgendaEvent, item interface{}, loc *time.Location) error {
+	date := api.Fields.Date.text(item)
+	expression := api.Fields.Time.text(item)
+
+	if api.Fields.Date.Layout == "" {
+		err := setEventDateTime(a, event, strings.TrimSpace(date+" "+expression))
+		if err != nil {
+			return err
+		}
+	} else {
+		parsed, err := time.ParseInLocation(api.Fields.Date.Layout, date, loc)
+		if err != nil {
+			return err
+		}
+
+		y, m, d := parsed.Date()
+		setEventTimeOn(a, event, time.Date(y, m, d, 0, 0, 0, 0, loc), expression)
+		if expression == "" && strings.Contains(api.Fields.Date.Layout, "15") {
+			event.Date = parsed
+			event.AllDay = false
+			event.TimePrecision = models.MinutePrecision
+		}
+	}
+
+	end := api.Fields.EndDate.text(item)
+	if end != "" && api.Fields.EndDate.Layout != "" {
+		parsed, err := time.ParseInLocation(api.Fields.EndDate.Layout, end, loc)
+		if err != nil {
+			return err
+		}
+
+		if parsed.After(event.Date) {
+			event.EndDate = &parsed
+		}
+	}
+
+	return nil
+}
+
+// text returns the value of the field in an event, or an empty string if not found. The
+// values of lists are joined with commas
+func (f JSONField) text(item interface{}) string {
+	return strings.Join(f.texts(item), ", ")
+}
+
+// texts returns the values of the field in an event, with the item path applied to each
+// element of lists, or a single value otherwise
+func (f JSONField) texts(item interface{}) []string {
+	if f.Path == "" {
+		return []string{}
+	}
+
+	values := []interface{}{search(item, f.Path)}
+	if list, ok := values[0].([]interface{}); ok {
+		values = list
+	}
+
+	texts := []string{}
+	for _, value := range values {
+		if f.Item != "" {
+			value = search(value, f.Item)
+		}
+
+		if text := f.transform(jsonText(value)); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	return texts
+}
+
+// transform applies the prefixes, the transforms and the map of the field to a value
+func (f JSONField) transform(text string) string {
+	for _, prefix := range f.Prefixes {
+		text = strings.ReplaceAll(text, prefix, "")
+	}
+
+	for _, transform := range f.Transforms {
+		switch transform {
+		case LowerTransform:
+			text = strings.ToLower(text)
+		case StripTagsTransform:
+			text = tagRegexp.ReplaceAllString(text, " ")
+		case TitleTransform:
+			text = strings.Title(strings.ToLower(text))
+		case UpperTransform:
+			text = strings.ToUpper(text)
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if mapped, ok := f.Map[text]; ok {
+		return mapped
+	}
+
+	return text
+}
+
+// search returns the value of a JMESPath expression, as "result.records" or
+// "items[0].title", in a JSON value, or nil if not found. The empty expression is the value
+// itself. The expressions are checked when the sources are loaded
+func search(value interface{}, expression string) interface{} {
+	if expression == "" {
+		return value
+	}
+
+	result, err := jmespath.Search(expression, value)
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
+
+// jsonText returns the text of a JSON scalar, or an empty string for objects, lists and nulls
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return ""
+}
+
+func (api *JSONAPI) validate() error {
+	if api.URL == "" {
+		return fmt.Errorf("the JSON API has no URL")
+	}
+	if api.Fields.Date.Path == "" {
+		return fmt.Errorf("the JSON API has no date field")
+	}
+	if api.Fields.EndDate.Path != "" && api.Fields.EndDate.Layout == "" {
+		return fmt.Errorf("the end date of the JSON API has no layout")
+	}
+
+	for _, layout := range []string{api.DateFormat, api.Fields.Date.Layout, api.Fields.EndDate.Layout} {
+		err := validateLayout(layout)
+		if err != nil {
+			return err
+		}
+	}
+
+	expressions := []string{api.Events}
+	fields := append([]JSONField{
+		api.Fields.Owner, api.Fields.Date, api.Fields.Time, api.Fields.EndDate,
+		api.Fields.FullDescription, api.Fields.Location, api.Fields.DetailURL, api.Fields.Attendees,
+	}, api.Fields.Description...)
+	for _, field := range fields {
+		expressions = append(expressions, field.Path, field.Item)
+	}
+
+	for _, expression := range expressions {
+		if expression == "" {
+			continue
+		}
+
+		_, err := jmespath.Compile(expression)
+		if err != nil {
+			return fmt.Errorf("wrong JMESPath expression %q of the JSON API: %v", expression, err)
+		}
+	}
+
+	return nil
+}
+
+// validateLayout checks that a layout, if any, has the day, the month and the year, as
+// "2006-01-02" or "02/01/2006 15:04"
+func validateLayout(layout string) error {
+	if layout == "" {
+		return nil
+	}
+
+	reference := time.Date(2020, 3, 2, 10, 30, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, reference.Format(layout))
+	if err != nil {
+		return fmt.Errorf("wrong layout %q of the JSON API: %v", layout, err)
+	}
+
+	y, m, d := parsed.Date()
+	if y != reference.Year() || m != reference.Month() || d != reference.Day() {
+		return fmt.Errorf("the layout %q of the JSON API has no day, month and year", layout)
+	}
+
+	return nil
+}
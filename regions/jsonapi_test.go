@@ -0,0 +1,184 @@
+package regions
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+)
+
+const jsonResponse = `{
+	"result": {
+		"records": [
+			{
+				"fecha": "2020-03-02T10:00:00",
+				"titulo": "<p>Reunión con el sector</p>",
+				"lugar": "Toledo",
+				"cargo": "PRESIDENTE",
+				"asistentes": [{"nombre": "Ana García"}, {"nombre": "Luis Pérez"}]
+			},
+			{
+				"fecha": "2020-03-03",
+				"hora": "de 10:00 a 12:00 horas",
+				"titulo": "Visita institucional",
+				"cargo": "CONSEJERO DE SANIDAD"
+			},
+			{
+				"fecha": "no es una fecha",
+				"titulo": "Evento sin fecha"
+			}
+		]
+	}
+}`
+
+func testJSONAPI() *JSONAPI {
+	return &JSONAPI{
+		URL:    "http://localhost/api?desde={from}&hasta={until}",
+		Events: "result.records",
+		Fields: JSONFields{
+			Owner: JSONField{Path: "cargo", Transforms: []string{TitleTransform}},
+			Date:  JSONField{Path: "fecha", Layout: "2006-01-02T15:04:05"},
+			Time:  JSONField{Path: "hora"},
+			Description: []JSONField{
+				{Path: "titulo", Transforms: []string{StripTagsTransform}},
+			},
+			Location:  JSONField{Path: "lugar"},
+			Attendees: JSONField{Path: "asistentes", Item: "nombre"},
+		},
+	}
+}
+
+func TestJSONAPIProcessor(t *testing.T) {
+	loc, err := timeparse.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := testJSONAPI()
+	api.Fields.Date.Layout = "2006-01-02"
+
+	agenda := &models.Agenda{
+		ID:     "json-2020-03-02",
+		Date:   time.Date(2020, 3, 2, 0, 0, 0, 0, loc),
+		Day:    models.AgendaDate{Day: 2, Month: 3, Year: 2020},
+		Until:  time.Date(2020, 3, 8, 0, 0, 0, 0, loc),
+		Owner:  "Presidente",
+		Region: "Castilla-La Mancha",
+		Events: []models.AgendaEvent{},
+	}
+
+	body := []byte(`{"result": {"records": [
+		{"fecha": "2020-03-03", "hora": "de 10:00 a 12:00 horas", "titulo": "<p>Visita</p>", "cargo": "CONSEJERO DE SANIDAD",
+		 "asistentes": [{"nombre": "Ana García"}, {"nombre": "Luis Pérez"}], "lugar": "Toledo"},
+		{"fecha": "no es una fecha", "titulo": "Evento sin fecha"},
+		{"fecha": "2020-03-09", "titulo": "Evento de la semana siguiente"}
+	]}}`)
+	api.processor("json", "Presidente", loc)(agenda, body)
+
+	if len(agenda.Events) != 1 {
+		t.Fatalf("unexpected events %+v", agenda.Events)
+	}
+
+	event := agenda.Events[0]
+	if event.Owner != "Consejero De Sanidad" || event.Description != "Visita" || event.Location != "Toledo" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if !event.Date.Equal(time.Date(2020, 3, 3, 10, 0, 0, 0, loc)) || event.EndDate == nil || event.EndDate.Hour() != 12 {
+		t.Errorf("unexpected times %v - %v", event.Date, event.EndDate)
+	}
+	if len(event.Attendance) != 2 || event.Attendance[1].FullName != "Luis Pérez" {
+		t.Errorf("unexpected attendees %+v", event.Attendance)
+	}
+}
+
+func TestJSONAPIDateTime(t *testing.T) {
+	loc, err := timeparse.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agenda := &models.Agenda{
+		ID:     "json-2020-03-02",
+		Date:   time.Date(2020, 3, 2, 0, 0, 0, 0, loc),
+		Day:    models.AgendaDate{Day: 2, Month: 3, Year: 2020},
+		Until:  time.Date(2020, 3, 8, 0, 0, 0, 0, loc),
+		Owner:  "Presidente",
+		Events: []models.AgendaEvent{},
+	}
+	testJSONAPI().processor("json", "Presidente", loc)(agenda, []byte(jsonResponse))
+
+	if len(agenda.Events) == 0 {
+		t.Fatal("no events")
+	}
+
+	event := agenda.Events[0]
+	if !event.Date.Equal(time.Date(2020, 3, 2, 10, 0, 0, 0, loc)) || event.TimePrecision != models.MinutePrecision {
+		t.Errorf("unexpected time %v, %s", event.Date, event.TimePrecision)
+	}
+	if event.Owner != "Presidente" || event.Description != "Reunión con el sector" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestJSONAPIExpand(t *testing.T) {
+	api := &JSONAPI{DateFormat: "02/01/2006"}
+	from := time.Date(2020, 3, 2, 0, 0, 0, 0, time.Local)
+	until := time.Date(2020, 3, 8, 0, 0, 0, 0, time.Local)
+
+	if url := api.expand("http://localhost/?from={from}&until={until}", from, until); url != "http://localhost/?from=02/03/2020&until=08/03/2020" {
+		t.Errorf("unexpected URL %s", url)
+	}
+}
+
+func TestJSONAPISearch(t *testing.T) {
+	var value interface{}
+	err := json.Unmarshal([]byte(jsonResponse), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"result.records[0].lugar", "Toledo"},
+		{"result.records[0].asistentes[-1].nombre", "Luis Pérez"},
+		{"result.records[?cargo == 'CONSEJERO DE SANIDAD'].hora | [0]", "de 10:00 a 12:00 horas"},
+		{"result.records[5].lugar", ""},
+		{"result.unknown", ""},
+	}
+
+	for _, test := range tests {
+		if text := jsonText(search(value, test.expression)); text != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.expression, test.expected, text)
+		}
+	}
+}
+
+func TestJSONAPIValidate(t *testing.T) {
+	if err := testJSONAPI().validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(api *JSONAPI)
+	}{
+		{"wrong events", func(api *JSONAPI) { api.Events = "result.records[" }},
+		{"wrong item", func(api *JSONAPI) { api.Fields.Attendees.Item = "nombre)" }},
+		{"wrong description", func(api *JSONAPI) { api.Fields.Description[0].Path = "[?titulo" }},
+		{"layout without date", func(api *JSONAPI) { api.Fields.Date.Layout = "15:04" }},
+		{"layout without year", func(api *JSONAPI) { api.DateFormat = "02/01" }},
+		{"end date without layout", func(api *JSONAPI) { api.Fields.EndDate.Path = "fin" }},
+	}
+
+	for _, test := range tests {
+		api := testJSONAPI()
+		test.change(api)
+		if err := api.validate(); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
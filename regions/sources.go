@@ -0,0 +1,144 @@
+package regions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/mdelapenya/cansino/holidays"
+	models "github.com/mdelapenya/cansino/models"
+)
+
+// Types of the sources
+const (
+	DrupalSource = "drupal"
+	ICSSource    = "ics"
+	JSONSource   = "json"
+)
+
+// Source represents the agenda of a region published with a generic mechanism, as a Drupal
+// view, an iCalendar feed or a JSON API, so that new regions need only configuration
+type Source struct {
+	Region         string         `json:"region"`
+	Type           string         `json:"type"`
+	IDPrefix       string         `json:"idPrefix"`
+	AllowedDomains []string       `json:"allowedDomains"`
+	StartDate      string         `json:"startDate"` // yyyy-MM-dd
+	Granularity    string         `json:"granularity"`
+	Owners         []models.Owner `json:"owners"`
+	Drupal         *DrupalView    `json:"drupal,omitempty"`
+	ICS            *ICSFeed       `json:"ics,omitempty"`
+	JSON           *JSONAPI       `json:"json,omitempty"`
+}
+
+// sources are the sources of the regions configured with a sources file, by region name
+var sources = map[string]*Source{}
+
+// LoadSources reads the sources of extra regions from a JSON file, making them available to
+// the factories, and returns the names of their regions
+func LoadSources(path string) ([]string, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := []*Source{}
+	err = json.Unmarshal(bytes, &loaded)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, source := range loaded {
+		err := source.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		sources[source.Region] = source
+		names = append(names, source.Region)
+	}
+
+	return names, nil
+}
+
+// NewRegion returns the region of the source
+func (s *Source) NewRegion() (*models.Region, error) {
+	start, err := time.Parse("2006-01-02", s.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("wrong start date of the source of %s: %v", s.Region, err)
+	}
+
+	// feeds have the events of every day
+	granularity := s.Granularity
+	if granularity == "" && s.Type == ICSSource {
+		granularity = models.MonthGranularity
+	}
+
+	owners := s.Owners
+	if len(owners) == 0 {
+		owners = []models.Owner{{Name: "Presidente"}}
+	}
+
+	return &models.Region{
+		Name:        s.Region,
+		DoPost:      s.Type == DrupalSource || (s.JSON != nil && s.JSON.Payload != ""),
+		Granularity: granularity,
+		StartDate: models.AgendaDate{
+			Day: start.Day(), Month: int(start.Month()), Year: start.Year(),
+		},
+		Holidays: holidays.NewCalendar(s.Region),
+		Owners:   owners,
+	}, nil
+}
+
+// NewAgenda returns the agenda of an owner of the region of the source, from a day until
+// another one
+func (s *Source) NewAgenda(region *models.Region, owner models.Owner, from time.Time, until time.Time) (*models.Agenda, error) {
+	switch s.Type {
+	case DrupalSource:
+		return NewAgendaDrupal(region, owner, s, from, until)
+	case ICSSource:
+		return NewAgendaICS(region, owner, s, from, until)
+	case JSONSource:
+		return NewAgendaJSON(region, owner, s, from, until)
+	}
+
+	return nil, fmt.Errorf("unsupported source type %s", s.Type)
+}
+
+func (s *Source) validate() error {
+	if s.Region == "" || s.IDPrefix == "" {
+		return fmt.Errorf("the sources need a region and an ID prefix")
+	}
+
+	switch s.Type {
+	case DrupalSource:
+		if s.Drupal == nil {
+			return fmt.Errorf("the source of %s has no Drupal view", s.Region)
+		}
+
+		err := s.Drupal.validate()
+		if err != nil {
+			return fmt.Errorf("the source of %s: %v", s.Region, err)
+		}
+	case ICSSource:
+		if s.ICS == nil {
+			return fmt.Errorf("the source of %s has no iCalendar feed", s.Region)
+		}
+	case JSONSource:
+		if s.JSON == nil {
+			return fmt.Errorf("the source of %s has no JSON API", s.Region)
+		}
+
+		err := s.JSON.validate()
+		if err != nil {
+			return fmt.Errorf("the source of %s: %v", s.Region, err)
+		}
+	default:
+		return fmt.Errorf("unsupported source type %s of %s", s.Type, s.Region)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package regions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+	log "github.com/sirupsen/logrus"
+)
+
+// time expressions at the beginning of a text, followed by a hyphen, as "10:00 - 12:00 h - "
+var timePrefixRegexp = regexp.MustCompile(`^\s*(\d{1,2}[:.]\d{2}(?:\s*(?:-|a|hasta)\s*\d{1,2}[:.]\d{2})?(?:\s*(?:h\.?|horas))?)\s*-\s*`)
+
+// dates at the beginning of a time expression, as "19/08/2019 - 10:00", "2019-08-19 10:00"
+// or "lunes, 19 de agosto de 2019, 10:00", in the agendas of several days
+var datePrefixRegexp = regexp.MustCompile(`(?i)^\s*(\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}/\d{4}|(?:\pL+,?\s+)?\d{1,2}\s+de\s+\pL+\.?(?:\s+de)?\s+\d{4})\s*[-,]?\s*`)
+
+// setEventTime sets the date, the end date and the time precision of an event of the day of
+// the agenda from a Spanish time expression, as "10:00", "10:00 - 12:00 h", "todo el día"
+// or "a lo largo de la mañana". Events without time, or whose time cannot be parsed, are
+// all-day events
+func setEventTime(a *models.Agenda, event *models.AgendaEvent, expression string) {
+	day := time.Date(a.Day.Year, time.Month(a.Day.Month), a.Day.Day, 0, 0, 0, 0, a.Date.Location())
+	setEventTimeOn(a, event, day, expression)
+}
+
+// setEventDateTime sets the date and the time of an event from an expression starting with
+// its date, as "19/08/2019 - 10:00", "2019-08-19 10:00" or "lunes, 19 de agosto de 2019,
+// 10:00". Agendas of several days need the date, as their events cannot be placed on any
+// day without it, while the date of agendas of a single day, even if requested as a range of
+// one day, defaults to their day
+func setEventDateTime(a *models.Agenda, event *models.AgendaEvent, expression string) error {
+	loc := datePrefixRegexp.FindStringSubmatchIndex(expression)
+	if loc == nil {
+		if a.Until.After(a.Date) {
+			return fmt.Errorf("no date in %q", expression)
+		}
+
+		setEventTime(a, event, expression)
+		return nil
+	}
+
+	date, err := timeparse.ParseDate(expression[loc[2]:loc[3]], a.Date.Location())
+	if err != nil {
+		return err
+	}
+
+	setEventTimeOn(a, event, date, expression[loc[1]:])
+	return nil
+}
+
+// setEventTimeOn sets the date, the end date and the time precision of an event of a day
+// from a Spanish time expression
+func setEventTimeOn(a *models.Agenda, event *models.AgendaEvent, day time.Time, expression string) {
+	event.Date = day
+	event.EndDate = nil
+	event.AllDay = true
+	event.TimePrecision = models.DayPrecision
+
+	if strings.TrimSpace(expression) == "" {
+		return
+	}
+
+	r, err := timeparse.ParseRange(expression)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"agendaID": a.ID,
+			"error":    err,
+		}).Warn("Cannot parse the time of the event. Considering it an all-day event")
+		return
+	}
+
+	event.Date, event.EndDate = r.Times(day)
+	event.AllDay = r.AllDay
+	event.TimePrecision = r.Precision
+}
+
+// splitTime splits a text starting with a time expression and a hyphen, as "10:00 - 12:00 h
+// - Reunión", into the time expression and the rest of the text. Without a time, the text
+// is split at the first hyphen, as in "Todo el día - Reunión"
+func splitTime(text string) (string, string) {
+	if loc := timePrefixRegexp.FindStringSubmatchIndex(text); loc != nil {
+		return text[loc[2]:loc[3]], strings.TrimSpace(text[loc[1]:])
+	}
+
+	firstHyphen := strings.Index(text, "-")
+	if firstHyphen < 0 {
+		return "", strings.TrimSpace(text)
+	}
+
+	return strings.TrimSpace(text[:firstHyphen]), strings.TrimSpace(text[firstHyphen+1:])
+}
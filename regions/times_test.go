@@ -0,0 +1,64 @@
+package regions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+	"github.com/mdelapenya/cansino/timeparse"
+)
+
+func TestSetEventDateTime(t *testing.T) {
+	loc, err := timeparse.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := &models.Agenda{
+		ID:   "test-2020-03-02",
+		Date: time.Date(2020, 3, 2, 0, 0, 0, 0, loc),
+		Day:  models.AgendaDate{Day: 2, Month: 3, Year: 2020},
+	}
+	days := &models.Agenda{}
+	*days = *day
+	days.Until = time.Date(2020, 3, 8, 0, 0, 0, 0, loc)
+	// a range of one day, as the agendas requested day by day with a range
+	oneDay := &models.Agenda{}
+	*oneDay = *day
+	oneDay.Until = day.Date
+
+	tests := []struct {
+		agenda     *models.Agenda
+		expression string
+		date       time.Time
+		fails      bool
+	}{
+		{days, "04/03/2020 - 10:00", time.Date(2020, 3, 4, 10, 0, 0, 0, loc), false},
+		{days, "jueves, 5 de marzo de 2020, 12:30", time.Date(2020, 3, 5, 12, 30, 0, 0, loc), false},
+		{days, "2020-03-06", time.Date(2020, 3, 6, 0, 0, 0, 0, loc), false},
+		// the weekday does not match the date
+		{days, "lunes, 5 de marzo de 2020, 12:30", time.Time{}, true},
+		{days, "31/02/2020 - 10:00", time.Time{}, true},
+		{days, "10:00", time.Time{}, true},
+		{day, "10:00", time.Date(2020, 3, 2, 10, 0, 0, 0, loc), false},
+		{oneDay, "10:00", time.Date(2020, 3, 2, 10, 0, 0, 0, loc), false},
+		{oneDay, "03/03/2020 - 10:00", time.Date(2020, 3, 3, 10, 0, 0, 0, loc), false},
+	}
+
+	for _, test := range tests {
+		event := models.AgendaEvent{}
+		err := setEventDateTime(test.agenda, &event, test.expression)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", test.expression, event.Date)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.expression, err)
+		} else if !event.Date.Equal(test.date) {
+			t.Errorf("%q: expected %v, got %v", test.expression, test.date, event.Date)
+		}
+	}
+}
@@ -1,29 +1,86 @@
 package regions
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/gocolly/colly/v2"
+	"github.com/mdelapenya/cansino/analysis"
 	"github.com/mdelapenya/cansino/models"
 )
 
-// AgendaFactory returns an agenda object based on its name
-func AgendaFactory(region *models.Region, day int, month int, year int) (*models.Agenda, error) {
+// AgendaFactory returns the agenda of an owner of a region, based on its name or on the
+// source of the region, if loaded
+func AgendaFactory(region *models.Region, owner models.Owner, day int, month int, year int) (*models.Agenda, error) {
+	if source, ok := sources[region.Name]; ok {
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		agenda, err := source.NewAgenda(region, owner, date, date)
+		if err != nil {
+			return nil, err
+		}
+
+		agenda.Until = time.Time{}
+		return agenda, nil
+	}
+
 	if region.Name == "Castilla-La Mancha" {
-		return NewAgendaCLM(region, day, month, year), nil
+		return NewAgendaCLM(region, owner, day, month, year)
 	} else if region.Name == "Castilla-León" {
-		return NewAgendaCYL(region, day, month, year), nil
+		return NewAgendaCYL(region, owner, day, month, year)
 	} else if region.Name == "Extremadura" {
-		return NewAgendaExtremadura(region, day, month, year), nil
+		return NewAgendaExtremadura(region, owner, day, month, year)
 	} else if region.Name == "Madrid" {
-		return NewAgendaMadrid(region, day, month, year), nil
+		return NewAgendaMadrid(region, owner, day, month, year)
 	}
 
 	return &models.Agenda{}, errors.New("No such region")
 }
 
+// AgendaRangeFactory returns the agenda of an owner of a region for a range of days, for
+// the regions fetching several days per request, or the agenda of the first day otherwise
+func AgendaRangeFactory(region *models.Region, owner models.Owner, from time.Time, until time.Time) (*models.Agenda, error) {
+	if source, ok := sources[region.Name]; ok {
+		return source.NewAgenda(region, owner, from, until)
+	}
+
+	if region.Name == "Madrid" {
+		return NewAgendaMadridRange(region, owner, from, until)
+	}
+
+	return AgendaFactory(region, owner, from.Day(), int(from.Month()), from.Year())
+}
+
+// BatchEnd returns the last day fetched with a day in a request of the granularity: the
+// Sunday of its week, or the last day of its month, but not after the end date
+func BatchEnd(granularity string, date time.Time, end time.Time) time.Time {
+	last := date
+	switch granularity {
+	case models.WeekGranularity:
+		last = date.AddDate(0, 0, (7-int(date.Weekday()))%7)
+	case models.MonthGranularity:
+		last = date.AddDate(0, 1, -date.Day())
+	}
+
+	if last.After(end) {
+		return end
+	}
+
+	return last
+}
+
 // RegionFactory returns a region based on its name
 func RegionFactory(name string) (*models.Region, error) {
+	if source, ok := sources[name]; ok {
+		return source.NewRegion()
+	}
+
 	if name == "Castilla-La Mancha" {
 		return CLM(), nil
 	} else if name == "Castilla-León" {
@@ -56,3 +113,82 @@ func RangeDate(start, end time.Time) func() time.Time {
 		return date
 	}
 }
+
+// eventID returns the ID of an event, from the prefix of the region, the owner and the
+// date. The events of the president of the region have no owner in their IDs, as it was
+// the only owner when the first events were indexed. The events without a time to the
+// minute, sharing the date with the rest of the all-day or part-of-day events of the owner,
+// add a short hash of their description
+func eventID(prefix string, president string, event *models.AgendaEvent) string {
+	date := event.Date.Local().Format("2006-01-02T15:04:05-0700")
+	if event.TimePrecision != "" && event.TimePrecision != models.MinutePrecision {
+		sum := sha1.Sum([]byte(analysis.Normalize(event.OriginalDescription)))
+		date += "-" + hex.EncodeToString(sum[:])[:8]
+	}
+
+	owner := analysis.Normalize(event.Owner)
+	if owner == analysis.Normalize(president) {
+		return prefix + "-" + date
+	}
+
+	return prefix + "-" + strings.ReplaceAll(owner, " ", "-") + "-" + date
+}
+
+// president returns the name of the owner of the agenda of the president of a region
+func president(region *models.Region) string {
+	if len(region.Owners) == 0 {
+		return ""
+	}
+
+	return region.Owners[0].Name
+}
+
+// LoadOwners reads the extra owners of the regions from a JSON file, as
+// {"Castilla-La Mancha": [{"id": "198", "name": "Presidente"}]}
+func LoadOwners(path string) (map[string][]models.Owner, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := map[string][]models.Owner{}
+	err = json.Unmarshal(bytes, &owners)
+	if err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
+
+// discoverOwners returns the officials linked from the index of the agendas of a source,
+// identified by the first group of the pattern in the links, as "/agenda/(\d+)", and named
+// after the text of the links. The index is requested as the agendas of the source, with the
+// delay, but not cached, so that the officials appointed since the last run are found
+func discoverOwners(indexURL string, pattern *regexp.Regexp, delay time.Duration) ([]models.Owner, error) {
+	owners := []models.Owner{}
+	seen := map[string]bool{}
+
+	index, err := url.Parse(indexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c := models.NewCollector([]string{index.Hostname()}, delay, false, false)
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		matches := pattern.FindStringSubmatch(e.Attr("href"))
+		name := strings.Join(strings.Fields(e.Text), " ")
+		if matches == nil || name == "" || seen[matches[1]] {
+			return
+		}
+
+		seen[matches[1]] = true
+		owners = append(owners, models.Owner{ID: matches[1], Name: name})
+	})
+
+	err = c.Visit(indexURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
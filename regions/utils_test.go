@@ -0,0 +1,77 @@
+package regions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdelapenya/cansino/models"
+)
+
+func TestEventID(t *testing.T) {
+	date := time.Date(2020, 6, 1, 0, 0, 0, 0, time.Local)
+	event := func(owner string, precision string, description string) *models.AgendaEvent {
+		return &models.AgendaEvent{
+			Date:                date,
+			Owner:               owner,
+			TimePrecision:       precision,
+			OriginalDescription: description,
+		}
+	}
+
+	meeting := eventID("clm", "Presidente", event("Presidente", models.MinutePrecision, "Reunión"))
+	if meeting != "clm-"+date.Format("2006-01-02T15:04:05-0700") {
+		t.Errorf("unexpected ID %s", meeting)
+	}
+
+	councillor := eventID("clm", "Presidente", event("Consejero de Sanidad", models.MinutePrecision, "Reunión"))
+	if !strings.HasPrefix(councillor, "clm-consejero-de-sanidad-") {
+		t.Errorf("unexpected ID %s", councillor)
+	}
+
+	cortes := eventID("clm", "Presidente", event("Presidente de las Cortes", models.MinutePrecision, "Reunión"))
+	if !strings.HasPrefix(cortes, "clm-presidente-de-las-cortes-") {
+		t.Errorf("unexpected ID %s", cortes)
+	}
+
+	visit := eventID("clm", "Presidente", event("Presidente", models.DayPrecision, "Visita a Toledo"))
+	inauguration := eventID("clm", "Presidente", event("Presidente", models.DayPrecision, "Inauguración en Cuenca"))
+	morning := eventID("clm", "Presidente", event("Presidente", models.PartOfDayPrecision, "Visita a Toledo"))
+	if visit == inauguration || visit == meeting || !strings.HasPrefix(visit, meeting+"-") {
+		t.Errorf("unexpected all-day IDs %s and %s", visit, inauguration)
+	}
+	if visit != morning {
+		t.Errorf("the hash depends on the precision: %s and %s", visit, morning)
+	}
+	if again := eventID("clm", "Presidente", event("Presidente", models.DayPrecision, "Visita a Toledo")); again != visit {
+		t.Errorf("unstable ID %s, was %s", again, visit)
+	}
+}
+
+func TestDiscoverOwners(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><ul>
+			<li><a href="/agenda/198">Presidente</a></li>
+			<li><a href="/agenda/201?page=1"> Consejero de
+				Sanidad </a></li>
+			<li><a href="/agenda/201">Consejero de Sanidad</a></li>
+			<li><a href="/agenda-historico/198">Histórico</a></li>
+			<li><a href="/agenda/">Agenda</a></li>
+		</ul></body></html>`)
+	}))
+	defer server.Close()
+
+	owners, err := discoverOwners(server.URL, regexp.MustCompile(`/agenda/(\d+)(?:[/?#]|$)`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []models.Owner{{ID: "198", Name: "Presidente"}, {ID: "201", Name: "Consejero de Sanidad"}}
+	if fmt.Sprint(owners) != fmt.Sprint(expected) {
+		t.Errorf("unexpected owners %v", owners)
+	}
+}
@@ -0,0 +1,266 @@
+// Package timeparse parses the Spanish dates and times used by the public agendas, as
+// "10:30", "10.30 h", "de 10:00 a 12:00 horas", "todo el día", "lunes" or
+// "2 de marzo de 2020", returning explicit errors instead of falling back to midnight
+package timeparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+)
+
+// Precisions of the parsed times, the same as the ones of the events
+const (
+	MinutePrecision    = "minute"
+	PartOfDayPrecision = "partOfDay"
+	DayPrecision       = "day"
+)
+
+// Error represents an expression that cannot be parsed
+type Error struct {
+	Expression string
+	Reason     string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cannot parse %q: %s", e.Expression, e.Reason)
+}
+
+// Location returns the time zone of the agendas
+func Location() (*time.Location, error) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the Europe/Madrid time zone: %v", err)
+	}
+
+	return loc, nil
+}
+
+// Clock represents a time of the day
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// On returns the time of the clock in a day
+func (c Clock) On(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, day.Location()).Add(time.Duration(c.Hour)*time.Hour + time.Duration(c.Minute)*time.Minute)
+}
+
+// times with minutes, as "10:30", "10.30 h" or "10:30 horas", or without them, as "10 h"
+var clockRegexp = regexp.MustCompile(`(?i)\b(\d{1,2})(?:[:.](\d{2})(?:\s*(?:h\.?|hrs?\.?|horas?)(?:\s|$|[^\pL]))?|\s*(?:h\.?|hrs?\.?|horas?)(?:\s|$|[^\pL]))`)
+
+// ParseTime parses a single time of the day, as "10:30", "10.30 h", "10:30 horas" or "10 h"
+func ParseTime(text string) (Clock, error) {
+	clocks, err := findClocks(text)
+	if err != nil {
+		return Clock{}, err
+	}
+	if len(clocks) != 1 {
+		return Clock{}, &Error{Expression: text, Reason: fmt.Sprintf("expected one time, found %d", len(clocks))}
+	}
+
+	return clocks[0], nil
+}
+
+func findClocks(text string) ([]Clock, error) {
+	clocks := []Clock{}
+	for _, indexes := range clockRegexp.FindAllStringSubmatchIndex(text, -1) {
+		// "10.000 personas" is not a time
+		if indexes[5] >= 0 && indexes[5] < len(text) && text[indexes[5]] >= '0' && text[indexes[5]] <= '9' {
+			continue
+		}
+
+		match := []string{text[indexes[2]:indexes[3]], ""}
+		if indexes[4] >= 0 {
+			match[1] = text[indexes[4]:indexes[5]]
+		}
+
+		hour, err := strconv.Atoi(match[0])
+		if err != nil {
+			return nil, &Error{Expression: text, Reason: err.Error()}
+		}
+
+		minute := 0
+		if match[1] != "" {
+			minute, err = strconv.Atoi(match[1])
+			if err != nil {
+				return nil, &Error{Expression: text, Reason: err.Error()}
+			}
+		}
+
+		if hour > 24 || minute > 59 || (hour == 24 && minute > 0) {
+			return nil, &Error{Expression: text, Reason: fmt.Sprintf("%02d:%02d is not a valid time", hour, minute)}
+		}
+
+		clocks = append(clocks, Clock{Hour: hour, Minute: minute})
+	}
+
+	return clocks, nil
+}
+
+// Range represents the parsed time of an event: a start time and, optionally, an end time,
+// a part of the day or the whole day
+type Range struct {
+	Start     Clock
+	End       *Clock
+	AllDay    bool
+	Precision string
+}
+
+// Times returns the start and end times of the range in a day. Ranges ending before they
+// start, as "22:00 - 01:00", end the next day
+func (r Range) Times(day time.Time) (time.Time, *time.Time) {
+	start := r.Start.On(day)
+	if r.End == nil {
+		return start, nil
+	}
+
+	end := r.End.On(day)
+	if end.Before(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return start, &end
+}
+
+// parts of the day, with their usual hours, more specific ones first
+var partsOfDay = []struct {
+	expression string
+	start      int
+	end        int
+}{
+	{"mediodia", 12, 16},
+	{"manana", 9, 14},
+	{"tarde", 16, 20},
+	{"noche", 20, 24},
+}
+
+var allDayExpressions = []string{"todo el dia", "toda la jornada", "jornada completa", "durante el dia"}
+
+// ParseRange parses the time expression of an event: a time, as "10:30 h", a range, as
+// "10:00 - 12:00 h" or "de 10.00 a 12.00 horas", the whole day, as "todo el día", or a
+// part of the day, as "a lo largo de la mañana"
+func ParseRange(text string) (Range, error) {
+	if strings.TrimSpace(text) == "" {
+		return Range{}, &Error{Expression: text, Reason: "empty expression"}
+	}
+
+	clocks, err := findClocks(text)
+	if err != nil {
+		return Range{}, err
+	}
+	if len(clocks) > 2 {
+		return Range{}, &Error{Expression: text, Reason: fmt.Sprintf("expected one or two times, found %d", len(clocks))}
+	}
+	if len(clocks) > 0 {
+		r := Range{Start: clocks[0], Precision: MinutePrecision}
+		if len(clocks) == 2 {
+			r.End = &clocks[1]
+		}
+		return r, nil
+	}
+
+	normalized := " " + analysis.Normalize(text) + " "
+	for _, allDay := range allDayExpressions {
+		if strings.Contains(normalized, " "+allDay+" ") {
+			return Range{AllDay: true, Precision: DayPrecision}, nil
+		}
+	}
+
+	for _, part := range partsOfDay {
+		if strings.Contains(normalized, " "+part.expression+" ") {
+			return Range{
+				Start:     Clock{Hour: part.start},
+				End:       &Clock{Hour: part.end},
+				Precision: PartOfDayPrecision,
+			}, nil
+		}
+	}
+
+	return Range{}, &Error{Expression: text, Reason: "no time found"}
+}
+
+var weekdays = map[string]time.Weekday{
+	"lunes": time.Monday, "martes": time.Tuesday, "miercoles": time.Wednesday,
+	"jueves": time.Thursday, "viernes": time.Friday, "sabado": time.Saturday, "domingo": time.Sunday,
+	"lun": time.Monday, "mar": time.Tuesday, "mie": time.Wednesday, "jue": time.Thursday,
+	"vie": time.Friday, "sab": time.Saturday, "dom": time.Sunday,
+}
+
+var months = map[string]time.Month{
+	"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+	"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+	"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+	"noviembre": time.November, "diciembre": time.December,
+	"ene": time.January, "feb": time.February, "mar": time.March, "abr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "ago": time.August, "sep": time.September,
+	"sept": time.September, "oct": time.October, "nov": time.November, "dic": time.December,
+}
+
+// ParseWeekday parses the Spanish name of a weekday, as "miércoles" or "mié."
+func ParseWeekday(name string) (time.Weekday, error) {
+	if weekday, ok := weekdays[analysis.Normalize(name)]; ok {
+		return weekday, nil
+	}
+
+	return time.Sunday, &Error{Expression: name, Reason: "unknown weekday"}
+}
+
+// ParseMonth parses the Spanish name of a month, as "marzo" or "mar."
+func ParseMonth(name string) (time.Month, error) {
+	if month, ok := months[analysis.Normalize(name)]; ok {
+		return month, nil
+	}
+
+	return time.January, &Error{Expression: name, Reason: "unknown month"}
+}
+
+var longDateRegexp = regexp.MustCompile(`(?i)^(?:(\pL+)\.?,?\s+)?(\d{1,2})\s+de\s+(\pL+)\.?(?:\s+de)?\s+(\d{4})$`)
+
+var dateLayouts = []string{"02/01/2006", "2/1/2006", "02-01-2006", "2006-01-02"}
+
+// ParseDate parses a date in the location, as "lunes, 2 de marzo de 2020", "2 de marzo de
+// 2020", "02/03/2020" or "2020-03-02". The weekday, if any, must match the date
+func ParseDate(text string, loc *time.Location) (time.Time, error) {
+	trimmed := strings.TrimSpace(text)
+
+	if matches := longDateRegexp.FindStringSubmatch(trimmed); matches != nil {
+		month, err := ParseMonth(matches[3])
+		if err != nil {
+			return time.Time{}, &Error{Expression: text, Reason: err.(*Error).Reason}
+		}
+		day, _ := strconv.Atoi(matches[2])
+		year, _ := strconv.Atoi(matches[4])
+
+		date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if date.Day() != day {
+			return time.Time{}, &Error{Expression: text, Reason: "the day does not exist"}
+		}
+
+		if matches[1] != "" {
+			weekday, err := ParseWeekday(matches[1])
+			if err != nil {
+				return time.Time{}, &Error{Expression: text, Reason: err.(*Error).Reason}
+			}
+			if weekday != date.Weekday() {
+				return time.Time{}, &Error{Expression: text, Reason: fmt.Sprintf("the day is not %s", matches[1])}
+			}
+		}
+
+		return date, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, trimmed, loc); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, &Error{Expression: text, Reason: "unsupported date format"}
+}
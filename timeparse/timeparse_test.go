@@ -0,0 +1,147 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	clock := func(hour int, minute int) *Clock {
+		return &Clock{Hour: hour, Minute: minute}
+	}
+
+	tests := []struct {
+		text      string
+		start     Clock
+		end       *Clock
+		allDay    bool
+		precision string
+	}{
+		{"10:00", Clock{10, 0}, nil, false, MinutePrecision},
+		{"10.30 h", Clock{10, 30}, nil, false, MinutePrecision},
+		{"10:30 horas", Clock{10, 30}, nil, false, MinutePrecision},
+		{"12 h.", Clock{12, 0}, nil, false, MinutePrecision},
+		{"de 10:00 a 12:00 horas", Clock{10, 0}, clock(12, 0), false, MinutePrecision},
+		{"10:00 - 12:00 h", Clock{10, 0}, clock(12, 0), false, MinutePrecision},
+		{"Todo el día", Clock{}, nil, true, DayPrecision},
+		{"Toda la jornada", Clock{}, nil, true, DayPrecision},
+		{"A lo largo de la mañana", Clock{9, 0}, clock(14, 0), false, PartOfDayPrecision},
+		{"Por la tarde", Clock{16, 0}, clock(20, 0), false, PartOfDayPrecision},
+		{"Al mediodía", Clock{12, 0}, clock(16, 0), false, PartOfDayPrecision},
+		{"Por la noche", Clock{20, 0}, clock(24, 0), false, PartOfDayPrecision},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRange(test.text)
+		if err != nil {
+			t.Errorf("ParseRange(%q) failed: %v", test.text, err)
+			continue
+		}
+
+		if r.Start != test.start || r.AllDay != test.allDay || r.Precision != test.precision {
+			t.Errorf("ParseRange(%q) = %+v", test.text, r)
+		}
+		if (r.End == nil) != (test.end == nil) || (r.End != nil && *r.End != *test.end) {
+			t.Errorf("ParseRange(%q) ends at %v, want %v", test.text, r.End, test.end)
+		}
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, text := range []string{"", "Reunión con 10.000 personas", "25:00", "10:75 h", "9:00, 10:00 y 11:00 h"} {
+		if r, err := ParseRange(text); err == nil {
+			t.Errorf("ParseRange(%q) = %+v, expected an error", text, r)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	clock, err := ParseTime("Hora: 09.15 h")
+	if err != nil || clock != (Clock{9, 15}) {
+		t.Errorf("ParseTime = %+v, %v", clock, err)
+	}
+
+	if _, err := ParseTime("de 10:00 a 12:00"); err == nil {
+		t.Error("ParseTime accepted two times")
+	}
+}
+
+func TestRangeTimes(t *testing.T) {
+	day := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	r, _ := ParseRange("22:00 - 01:00")
+	start, end := r.Times(day)
+	if !start.Equal(time.Date(2020, 3, 2, 22, 0, 0, 0, time.UTC)) || end == nil || !end.Equal(time.Date(2020, 3, 3, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected times %v and %v", start, end)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	loc := time.UTC
+	march2 := time.Date(2020, 3, 2, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		text string
+		date time.Time
+	}{
+		{"lunes, 2 de marzo de 2020", march2},
+		{"Lunes 2 de marzo de 2020", march2},
+		{"2 de marzo de 2020", march2},
+		{"2 de marzo 2020", march2},
+		{"lun., 2 de mar. de 2020", march2},
+		{"02/03/2020", march2},
+		{"2/3/2020", march2},
+		{"2020-03-02", march2},
+		{"29 de febrero de 2020", time.Date(2020, 2, 29, 0, 0, 0, 0, loc)},
+	}
+
+	for _, test := range tests {
+		date, err := ParseDate(test.text, loc)
+		if err != nil || !date.Equal(test.date) {
+			t.Errorf("ParseDate(%q) = %v, %v, want %v", test.text, date, err, test.date)
+		}
+	}
+
+	errors := []string{
+		"martes, 2 de marzo de 2020",
+		"31 de febrero de 2020",
+		"29 de febrero de 2019",
+		"2 de marzuelo de 2020",
+		"fulanes, 2 de marzo de 2020",
+		"32/03/2020",
+		"mañana",
+	}
+	for _, text := range errors {
+		if date, err := ParseDate(text, loc); err == nil {
+			t.Errorf("ParseDate(%q) = %v, expected an error", text, date)
+		}
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	tests := map[string]time.Month{
+		"enero": time.January, "Marzo": time.March, "mar.": time.March,
+		"setiembre": time.September, "Sept.": time.September, "DICIEMBRE": time.December,
+	}
+	for name, month := range tests {
+		if got, err := ParseMonth(name); err != nil || got != month {
+			t.Errorf("ParseMonth(%q) = %v, %v, want %v", name, got, err, month)
+		}
+	}
+
+	if _, err := ParseMonth("marzuelo"); err == nil {
+		t.Error("ParseMonth accepted an unknown month")
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	if weekday, err := ParseWeekday("Miércoles"); err != nil || weekday != time.Wednesday {
+		t.Errorf("ParseWeekday = %v, %v", weekday, err)
+	}
+	if weekday, err := ParseWeekday("sáb."); err != nil || weekday != time.Saturday {
+		t.Errorf("ParseWeekday = %v, %v", weekday, err)
+	}
+	if _, err := ParseWeekday("fulanes"); err == nil {
+		t.Error("ParseWeekday accepted an unknown weekday")
+	}
+}
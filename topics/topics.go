@@ -0,0 +1,217 @@
+package topics
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdelapenya/cansino/analysis"
+	"github.com/mdelapenya/cansino/models"
+)
+
+// Taxonomy represents the terms identifying each policy area, keyed by topic name.
+// A term ending with '*' matches any word starting with it. A term starting with '!' is a
+// phrase whose matches are ignored for the topic, as "!hospital* universitari*", so that
+// university hospitals are not education
+type Taxonomy map[string][]string
+
+// DefaultTaxonomy returns the built-in taxonomy of policy areas
+func DefaultTaxonomy() Taxonomy {
+	return Taxonomy{
+		"health": {
+			"sanidad", "sanitari*", "salud", "hospital*", "medico", "medicos", "medica", "medicas",
+			"medicina", "medicamento*", "enfermer*", "centro de salud", "consultorio*", "pandemia",
+			"covid*", "coronavirus", "vacuna*",
+		},
+		"education": {
+			"educacion", "educativ*", "colegio*", "escuela*", "instituto de educacion secundaria",
+			"institutos de educacion secundaria", "universidad*", "universitari*", "alumn*",
+			"docente*", "profesor*", "formacion profesional", "becas", "!hospital* universitari*",
+		},
+		"depopulation": {
+			"despoblacion", "despoblad*", "reto demografico", "medio rural", "mundo rural",
+			"zonas rurales", "repoblacion", "pequenos municipios", "espana vaciada",
+		},
+		"agriculture": {
+			"agricultura", "agricola*", "agricultor*", "agrari*", "ganader*", "regadio*",
+			"pac", "cosecha*", "vinos", "vinicola*", "vinedo*", "vendimia", "viticultor*",
+			"agroalimentari*", "cooperativa*",
+		},
+		"economy": {
+			"economia", "economic*", "empresa*", "empresari*", "empleo", "inversion*",
+			"industria*", "desempleo", "paro", "exportacion*", "pymes", "autonomos", "ceoe",
+		},
+	}
+}
+
+// LoadTaxonomy reads a taxonomy from a JSON file, in the form {"topic": ["term", ...]}
+func LoadTaxonomy(path string) (Taxonomy, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	taxonomy := Taxonomy{}
+	err = json.Unmarshal(bytes, &taxonomy)
+	if err != nil {
+		return nil, err
+	}
+
+	return taxonomy, nil
+}
+
+// Tagger tags texts with the policy areas of a taxonomy
+type Tagger struct {
+	terms    map[string][]string
+	excluded map[string][]*regexp.Regexp
+}
+
+// NewTagger returns a tagger for the taxonomy, normalising its terms
+func NewTagger(taxonomy Taxonomy) *Tagger {
+	terms := map[string][]string{}
+	excluded := map[string][]*regexp.Regexp{}
+	for topic, topicTerms := range taxonomy {
+		for _, term := range topicTerms {
+			if strings.HasPrefix(term, "!") {
+				if phrase := excludedPhrase(term[1:]); phrase != nil {
+					excluded[topic] = append(excluded[topic], phrase)
+				}
+				continue
+			}
+
+			prefix := strings.HasSuffix(term, "*")
+
+			normalized := analysis.Normalize(strings.TrimSuffix(term, "*"))
+			if normalized == "" {
+				continue
+			}
+
+			if prefix {
+				normalized = " " + normalized
+			} else {
+				normalized = " " + normalized + " "
+			}
+
+			terms[topic] = append(terms[topic], normalized)
+		}
+	}
+
+	return &Tagger{terms: terms, excluded: excluded}
+}
+
+// excludedPhrase returns the expression of a phrase of the taxonomy, whose words ending with
+// '*' match any word starting with them
+func excludedPhrase(phrase string) *regexp.Regexp {
+	words := []string{}
+	for _, word := range strings.Fields(phrase) {
+		prefix := strings.HasSuffix(word, "*")
+		word = regexp.QuoteMeta(analysis.Normalize(strings.TrimSuffix(word, "*")))
+		if prefix {
+			word += `\pL*`
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	return regexp.MustCompile(" " + strings.Join(words, " ") + " ")
+}
+
+// Tag returns the sorted topics with at least one term present in the text
+func (t *Tagger) Tag(text string) []string {
+	normalized := " " + analysis.Normalize(text) + " "
+
+	topics := []string{}
+	for topic, terms := range t.terms {
+		text := normalized
+		for _, phrase := range t.excluded[topic] {
+			text = phrase.ReplaceAllString(text, "  ")
+		}
+
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				topics = append(topics, topic)
+				break
+			}
+		}
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
+
+// TagEvent sets the topics of an event from its original description
+func (t *Tagger) TagEvent(event *models.AgendaEvent) {
+	event.Topics = t.Tag(event.OriginalDescription)
+}
+
+// Share represents how many events of an owner were tagged with a topic in a period
+type Share struct {
+	Owner  string  `json:"owner"`
+	Period string  `json:"period"`
+	Topic  string  `json:"topic"`
+	Events int     `json:"events"`
+	Total  int     `json:"total"`
+	Share  float64 `json:"share"`
+	// Hours spent in the events of the topic with known end date
+	Hours float64 `json:"hours"`
+}
+
+// Shares computes the topic shares per owner and period, where the period of an event
+// is obtained from its date. Events are tagged again, so that taxonomy changes do not
+// require to reindex them
+func (t *Tagger) Shares(events []models.AgendaEvent, period func(time.Time) string) []Share {
+	type key struct {
+		owner  string
+		period string
+	}
+
+	totals := map[key]int{}
+	counts := map[key]map[string]int{}
+	hours := map[key]map[string]float64{}
+	for _, event := range events {
+		k := key{owner: event.Owner, period: period(event.Date)}
+
+		totals[k]++
+		if counts[k] == nil {
+			counts[k] = map[string]int{}
+			hours[k] = map[string]float64{}
+		}
+		for _, topic := range t.Tag(event.OriginalDescription) {
+			counts[k][topic]++
+			hours[k][topic] += event.Duration().Hours()
+		}
+	}
+
+	shares := []Share{}
+	for k, total := range totals {
+		for topic, count := range counts[k] {
+			shares = append(shares, Share{
+				Owner:  k.owner,
+				Period: k.period,
+				Topic:  topic,
+				Events: count,
+				Total:  total,
+				Share:  float64(count) / float64(total),
+				Hours:  hours[k][topic],
+			})
+		}
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Owner != shares[j].Owner {
+			return shares[i].Owner < shares[j].Owner
+		}
+		if shares[i].Period != shares[j].Period {
+			return shares[i].Period < shares[j].Period
+		}
+		return shares[i].Topic < shares[j].Topic
+	})
+
+	return shares
+}
@@ -0,0 +1,57 @@
+package topics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	tagger := NewTagger(DefaultTaxonomy())
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Visita al Hospital Universitario de Toledo", []string{"health"}},
+		{"Visita a los hospitales universitarios y a la Universidad de Castilla-La Mancha", []string{"education", "health"}},
+		{"Reunión con los rectores universitarios", []string{"education"}},
+		{"Reunión con médicos de atención primaria", []string{"health"}},
+		{"Inauguración del Instituto de Educación Secundaria de Sigüenza", []string{"education"}},
+		{"Reunión con la directora del Instituto de la Mujer", []string{}},
+		{"Reunión con el Instituto de Finanzas", []string{}},
+		{"Presentación de la campaña de promoción de los vinos de La Mancha", []string{"agriculture"}},
+		{"El presidente vino a Toledo", []string{}},
+		{"Jornada sobre medicamentos genéricos", []string{"health"}},
+		{"Acto con la Asociación Mediterránea", []string{}},
+		{"Encuentro con empresarios sobre el reto demográfico", []string{"depopulation", "economy"}},
+		{"Visita a la pacífica comarca", []string{}},
+	}
+
+	for _, test := range tests {
+		got := tagger.Tag(test.text)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Tag(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestTagPrefixes(t *testing.T) {
+	tagger := NewTagger(Taxonomy{"health": {"hospital*"}, "economy": {"paro"}})
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hospitales", []string{"health"}},
+		{"El paro baja", []string{"economy"}},
+		{"Parodia", []string{}},
+		{"", []string{}},
+	}
+
+	for _, test := range tests {
+		got := tagger.Tag(test.text)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Tag(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}